@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package brago
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// Go runs [Bracket] in a new goroutine. The returned wait blocks until the bracket finishes and
+// returns its error.
+func Go[R any](acquire func() (R, error), release func(R) error, use func(R) error) (wait func() error) {
+	done := make(chan error, 1)
+	go func() { done <- Bracket(acquire, release, use) }()
+	return sync.OnceValue(func() error { return <-done })
+}
+
+// BracketAsync runs [Bracket] in a new goroutine. The returned channel delivers the error of the
+// bracket and is closed afterwards. A panic is recovered and delivered as a [PanicError], and if use
+// panics the resource is still released.
+func BracketAsync[R any](acquire func() (R, error), release func(R) error, use func(R) error) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		defer close(done)
+		done <- recovered(func() error { return Bracket(acquire, release, recoverUse(use)) })
+	}()
+	return done
+}
+
+// WithHandoff acquires the resource and hands its release over to the returned done, so the
+// resource can be released on another goroutine than the one which acquired it. The done releases
+// the resource only once, no matter how many goroutines call it, and returns the error passed to
+// it joined with the error of the release. Later calls return the same error.
+func WithHandoff[R any](acquire func() (R, error), release func(R) error) (r R, done func(error) error, err error) {
+	r, err = acquire()
+	if err != nil {
+		return Zero[R](), nil, err
+	}
+
+	var (
+		once   sync.Once
+		result error
+	)
+	return r, func(err error) error {
+		once.Do(func() { result = errors.Join(err, released(release(r))) })
+		return result
+	}, nil
+}
+
+// WithWorker runs work in a new goroutine while use runs. Both get the same context, which is
+// cancelled afterwards, and the worker is waited for, so it does not outlive the bracket. The error
+// of the worker is combined with the error of use like the error of a release. A worker which
+// returns [pkg/context.Canceled] after the cancellation is considered to have stopped cleanly.
+func WithWorker(work func(ctx context.Context) error, use func(context.Context) error) error {
+	type worker struct {
+		ctx    context.Context
+		cancel context.CancelFunc
+		done   chan error
+	}
+
+	return Bracket(
+		func() (worker, error) {
+			ctx, cancel := context.WithCancel(context.Background())
+			w := worker{ctx: ctx, cancel: cancel, done: make(chan error, 1)}
+			go func() { w.done <- work(ctx) }()
+			return w, nil
+		},
+		func(w worker) error {
+			w.cancel()
+			if err := <-w.done; !errors.Is(err, context.Canceled) {
+				return err
+			}
+			return nil
+		},
+		func(w worker) error { return use(w.ctx) },
+	)
+}
+
+// WithPool runs a pool of size workers while use runs. A size below 1 is treated as 1. Every worker
+// runs the functions passed to submit and, alongside them, task with the id of the worker from 0 to
+// size-1, e.g. to keep a per-worker connection alive for the lifetime of the pool. The submit
+// blocks until a worker is free. If any task fails, the context of the tasks is cancelled and the
+// workers skip the functions submitted from then on. Afterwards the pool is closed, the context of
+// the tasks is cancelled, so the tasks are told to stop, and the workers finish the submitted
+// functions and are waited for together with the tasks. The errors of the tasks are joined, except
+// for [pkg/context.Canceled] which the tasks return when they are told to stop. The submit MUST NOT
+// be called after use returns.
+func WithPool(size int, task func(ctx context.Context, id int) error, use func(submit func(func())) error) error {
+	type pool struct {
+		jobs   chan func()
+		cancel context.CancelFunc
+		failed atomic.Bool
+		wg     sync.WaitGroup
+		errs   []error
+	}
+
+	size = max(size, 1)
+	return Bracket(
+		func() (*pool, error) {
+			ctx, cancel := context.WithCancel(context.Background())
+			p := &pool{jobs: make(chan func()), cancel: cancel, errs: make([]error, size)}
+			for id := range size {
+				p.wg.Add(2)
+				go func() {
+					defer p.wg.Done()
+					if err := task(ctx, id); err != nil && !errors.Is(err, context.Canceled) {
+						p.errs[id] = err
+						p.failed.Store(true)
+						cancel()
+					}
+				}()
+				go func() {
+					defer p.wg.Done()
+					for job := range p.jobs {
+						// Only a failed task skips the functions, the cancellation at the
+						// shutdown MUST NOT drop the ones already submitted.
+						if !p.failed.Load() {
+							job()
+						}
+					}
+				}()
+			}
+			return p, nil
+		},
+		func(p *pool) error {
+			close(p.jobs)
+			p.cancel()
+			p.wg.Wait()
+			return errors.Join(p.errs...)
+		},
+		func(p *pool) error {
+			return use(func(job func()) { p.jobs <- job })
+		},
+	)
+}
+
+// AsyncRelease is like [Bracket], but it returns as soon as use finishes, while the resource is
+// released on a shared background goroutine. It suits latency-sensitive paths where waiting for a
+// slow release is unacceptable. The trade-off is that the returned error never includes the error
+// of the release, which is only reported to [OnReleaseError].
+func AsyncRelease[R any](acquire func() (R, error), release func(R) error, use func(R) error) error {
+	r, err := acquire()
+	if err != nil {
+		return err
+	}
+
+	err = use(r)
+	releases.push(func() { released(release(r)) })
+	return err
+}
+
+// releases is the queue of the releases scheduled by [AsyncRelease]. A single goroutine runs them
+// in order. It exits once the queue is empty and is started again by the next push.
+var releases releaseQueue
+
+type releaseQueue struct {
+	mu      sync.Mutex
+	jobs    []func()
+	running bool
+}
+
+func (q *releaseQueue) push(job func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.jobs = append(q.jobs, job)
+	if !q.running {
+		q.running = true
+		go q.run()
+	}
+}
+
+func (q *releaseQueue) run() {
+	for {
+		q.mu.Lock()
+		if len(q.jobs) == 0 {
+			q.running = false
+			q.mu.Unlock()
+			return
+		}
+		job := q.jobs[0]
+		q.jobs[0] = nil
+		q.jobs = q.jobs[1:]
+		q.mu.Unlock()
+
+		job()
+	}
+}
@@ -170,10 +170,32 @@ package brago
 
 import (
 	"errors"
+	"fmt"
 	"io"
+	"io/fs"
+	"net"
+	"sync"
 )
 
-// Bracket is used to manually acquire and release the resource.
+// OnReleaseError is called, if set, with every error of a release run by the brackets of the
+// package, even if the error of use is the one which gets returned. It is a central place to log or
+// count failed releases. It MUST be set before any bracket runs, e.g. in an init function.
+var OnReleaseError func(err error)
+
+// released reports the error of a release to [OnReleaseError] and returns it as a [ReleaseError].
+func released(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if OnReleaseError != nil {
+		OnReleaseError(err)
+	}
+	return &ReleaseError{Err: err}
+}
+
+// Bracket is used to manually acquire and release the resource. If both use and release fail,
+// their errors are combined according to [DefaultCombinePolicy].
 func Bracket[R any](acquire func() (R, error), release func(R) error, use func(R) error) error {
 	r, err := acquire()
 	if err != nil {
@@ -182,16 +204,10 @@ func Bracket[R any](acquire func() (R, error), release func(R) error, use func(R
 
 	if err = use(r); err != nil {
 		// MUST NOT leak the resource in case of an error!
-		if cerr := release(r); cerr != nil {
-			// TODO: decide the final version of error. The problem is: I don't want any left out,
-			// unreachable errors. However, errors.Join is available since 1.21 which makes it
-			// impossible to maintain backwards compatability. Ideally, both should be achieved.
-			return errors.Join(err, cerr)
-		}
-		return err
+		return DefaultCombinePolicy().Combine(err, released(release(r)))
 	}
 
-	return release(r)
+	return released(release(r))
 }
 
 // WithResource is used to manually acquire and automatically release the resource which implements
@@ -203,3 +219,362 @@ func WithResource[R io.Closer](acquire func() (R, error), use func(R) error) err
 		use,
 	)
 }
+
+// WithResourceSafe is like [WithResource], but use may close the resource itself. The use gets a
+// close which closes the resource only once, so the release does not close it again, and returns
+// the error of the first Close to every caller, including the bracket. If use calls Close of the
+// resource directly instead, the release tolerates it: an error of Close reporting that the
+// resource is already closed, [pkg/io/fs.ErrClosed] or [pkg/net.ErrClosed], is ignored, and a
+// panic of Close, e.g. by closing a closed channel, is recovered and returned as a [PanicError].
+func WithResourceSafe[R io.Closer](acquire func() (R, error), use func(r R, close func() error) error) error {
+	type guarded struct {
+		r     R
+		close func() error
+	}
+
+	return Bracket(
+		func() (guarded, error) {
+			r, err := acquire()
+			if err != nil {
+				return guarded{}, err
+			}
+			return guarded{r: r, close: sync.OnceValue(func() error { return closeTolerant(r) })}, nil
+		},
+		func(g guarded) error { return g.close() },
+		func(g guarded) error { return use(g.r, g.close) },
+	)
+}
+
+func closeTolerant(c io.Closer) error {
+	err := recovered(c.Close)
+	if errors.Is(err, fs.ErrClosed) || errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+// Zero returns the zero value of T. The brackets which return a value return the zero value
+// whenever they fail.
+func Zero[T any]() T {
+	var zero T
+	return zero
+}
+
+// BracketNamed is like [Bracket], but the errors of acquire and release are prefixed with the name.
+// The name is only formatted when one of them fails, so it may be computed lazily.
+func BracketNamed[R any](name fmt.Stringer, acquire func() (R, error), release func(R) error, use func(R) error) error {
+	return Bracket(
+		func() (R, error) {
+			r, err := acquire()
+			if err != nil {
+				return r, fmt.Errorf("%s: %w", name, err)
+			}
+			return r, nil
+		},
+		func(r R) error {
+			if err := release(r); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			return nil
+		},
+		use,
+	)
+}
+
+// Acquire2 is used to manually acquire the resource which implements io.Closer and to hand its
+// release over to another cleanup mechanism, e.g. defer or testing.T.Cleanup. The returned cleanup
+// closes the resource only once, later calls return the same error.
+func Acquire2[R io.Closer](acquire func() (R, error)) (R, func() error, error) {
+	r, err := acquire()
+	if err != nil {
+		return Zero[R](), nil, err
+	}
+
+	return r, sync.OnceValue(func() error { return released(r.Close()) }), nil
+}
+
+// AndThen composes two use functions into one which runs them in sequence on the same resource.
+// The second one is not run if the first one fails.
+func AndThen[R any](first func(R) error, second func(R) error) func(R) error {
+	return func(r R) error {
+		if err := first(r); err != nil {
+			return err
+		}
+		return second(r)
+	}
+}
+
+// BracketEither is like [Bracket], but if primary fails to acquire the resource, fallback is tried
+// instead. If both fail, their errors are joined.
+func BracketEither[R any](primary func() (R, error), fallback func() (R, error), release func(R) error, use func(R) error) error {
+	return Bracket(
+		func() (R, error) {
+			r, err := primary()
+			if err == nil {
+				return r, nil
+			}
+
+			r, ferr := fallback()
+			if ferr != nil {
+				return r, errors.Join(err, ferr)
+			}
+			return r, nil
+		},
+		release,
+		use,
+	)
+}
+
+// BracketValidate is like [Bracket], but validate checks the acquired resource before use runs. If
+// the validation fails, use is not run and the resource is released.
+func BracketValidate[R any](acquire func() (R, error), validate func(R) error, release func(R) error, use func(R) error) error {
+	return Bracket(acquire, release, AndThen(validate, use))
+}
+
+// BracketSink is like [Bracket], but the result of use is passed to sink before the resource is
+// released. The sink is not called if use fails.
+func BracketSink[R, A any](acquire func() (R, error), release func(R) error, use func(R) (A, error), sink func(A)) error {
+	return Bracket(acquire, release, func(r R) error {
+		a, err := use(r)
+		if err != nil {
+			return err
+		}
+
+		sink(a)
+		return nil
+	})
+}
+
+// BracketMulti is like [Bracket], but the resource is released in several steps, e.g. Flush and
+// Close. The steps run in reverse order, like deferred calls, and every step runs even if a
+// previous one fails.
+func BracketMulti[R any](acquire func() (R, error), use func(R) error, releases ...func(R) error) error {
+	return Bracket(
+		acquire,
+		func(r R) error {
+			errs := make([]error, 0, len(releases))
+			for i := len(releases) - 1; i >= 0; i-- {
+				errs = append(errs, releases[i](r))
+			}
+			return errors.Join(errs...)
+		},
+		use,
+	)
+}
+
+// WithCloserMap is like [WithResource], but for a map of resources which implement io.Closer.
+// Every value of the map is closed even if closing another one fails. The order of closing is
+// unspecified, like the order of iteration over a map.
+func WithCloserMap[K comparable](acquire func() (map[K]io.Closer, error), use func(map[K]io.Closer) error) error {
+	return Bracket(
+		acquire,
+		func(m map[K]io.Closer) error {
+			errs := make([]error, 0, len(m))
+			for _, c := range m {
+				errs = append(errs, c.Close())
+			}
+			return errors.Join(errs...)
+		},
+		use,
+	)
+}
+
+// BracketAccumulate is like [Bracket], but use appends its results to an accumulator. The results
+// accumulated so far are returned even if use fails midway.
+func BracketAccumulate[R, A any](acquire func() (R, error), release func(R) error, use func(R, *[]A) error) ([]A, error) {
+	var acc []A
+	err := Bracket(acquire, release, func(r R) error { return use(r, &acc) })
+	return acc, err
+}
+
+// BracketIf is like [Bracket], but nothing is acquired and nil is returned if cond is false.
+func BracketIf[R any](cond func() bool, acquire func() (R, error), release func(R) error, use func(R) error) error {
+	if !cond() {
+		return nil
+	}
+	return Bracket(acquire, release, use)
+}
+
+// WithChannel creates a channel with the given buffer size and passes its ends to use. Afterwards
+// the channel is closed, so the loops ranging over it end. The use MUST NOT close the channel
+// itself.
+func WithChannel[T any](buffer int, use func(chan<- T, <-chan T) error) error {
+	var once sync.Once
+
+	return Bracket(
+		func() (chan T, error) { return make(chan T, buffer), nil },
+		func(ch chan T) error {
+			once.Do(func() { close(ch) })
+			return nil
+		},
+		func(ch chan T) error { return use(ch, ch) },
+	)
+}
+
+// BracketPost is like [Bracket], but post observes the final error once the bracket finishes,
+// whether it failed or not, e.g. to record metrics.
+func BracketPost[R any](acquire func() (R, error), release func(R) error, post func(error), use func(R) error) error {
+	err := Bracket(acquire, release, use)
+	post(err)
+	return err
+}
+
+// BracketStaged is like [Bracket], but the acquired resource is set up further by stages, e.g.
+// authenticate and then subscribe. Every stage returns its own cleanup, which may be nil. The
+// cleanups of the stages which succeeded run in reverse order before the resource is released, and
+// their errors are treated like errors of the release. If a stage fails, the following stages and
+// use are not run.
+func BracketStaged[R any](stages []func(R) (func() error, error), acquire func() (R, error), release func(R) error, use func(R) error) error {
+	return Bracket(acquire, release, func(r R) error {
+		cleanups := make([]func() error, 0, len(stages))
+		err := func() error {
+			for _, stage := range stages {
+				cleanup, err := stage(r)
+				if err != nil {
+					return err
+				}
+				if cleanup != nil {
+					cleanups = append(cleanups, cleanup)
+				}
+			}
+			return use(r)
+		}()
+
+		// The cleanups are releases of the stages, so their errors are reported like those of
+		// the release.
+		errs := []error{err}
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			errs = append(errs, released(cleanups[i]()))
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// BracketR2 is like [Bracket], but use returns a value and the errors are not joined. It returns,
+// in order: the value of use, the error of use and the error of release. The value is returned
+// even if release fails. If acquire fails, its error is returned in place of the error of use.
+func BracketR2[R, A any](acquire func() (R, error), release func(R) error, use func(R) (A, error)) (A, error, error) {
+	r, err := acquire()
+	if err != nil {
+		return Zero[A](), err, nil
+	}
+
+	a, err := use(r)
+	return a, err, released(release(r))
+}
+
+// WithAny is like [WithResource], but the way to release the resource is detected at run time,
+// which suits code dealing with resources of unknown types. The resource is released by the first
+// method it has in this order:
+//
+//   - Close() error, i.e. io.Closer.
+//   - Stop(), like [pkg/time.Ticker.Stop].
+//   - Cancel().
+//
+// If it has none of them, nothing is released. The detection costs a few type assertions per call,
+// prefer the statically typed brackets where the type is known.
+func WithAny(acquire func() (any, error), use func(any) error) error {
+	return Bracket(
+		acquire,
+		func(r any) error {
+			switch r := r.(type) {
+			case io.Closer:
+				return r.Close()
+			case interface{ Stop() }:
+				r.Stop()
+			case interface{ Cancel() }:
+				r.Cancel()
+			}
+			return nil
+		},
+		use,
+	)
+}
+
+// BracketView is like [Bracket], but use only gets a restricted view of the resource, e.g. an
+// io.Reader of a file, so it cannot close or otherwise break the resource. The release still gets
+// the resource itself.
+func BracketView[R, V any](acquire func() (R, error), view func(R) V, release func(R) error, use func(V) error) error {
+	return Bracket(acquire, release, func(r R) error { return use(view(r)) })
+}
+
+// BracketCommit is like [Bracket], but the resource is released in one of two ways, like a
+// transaction. If use succeeds, prepare and then commit run. If use or prepare fails, rollback runs
+// instead. The errors are combined like in [Bracket].
+func BracketCommit[R any](acquire func() (R, error), prepare func(R) error, commit func(R) error, rollback func(R) error, use func(R) error) error {
+	r, err := acquire()
+	if err != nil {
+		return err
+	}
+
+	if err = AndThen(use, prepare)(r); err != nil {
+		return DefaultCombinePolicy().Combine(err, released(rollback(r)))
+	}
+
+	return released(commit(r))
+}
+
+// BracketVariadic is like [Bracket], but for a list of resources acquired by acquires in order. If
+// one of them fails, the resources acquired so far are released in reverse order. Otherwise all of
+// them are passed to use and released in reverse order afterwards.
+func BracketVariadic[R any](acquires []func() (R, error), release func(R) error, use func([]R) error) error {
+	releaseAll := func(rs []R) error {
+		errs := make([]error, 0, len(rs))
+		for i := len(rs) - 1; i >= 0; i-- {
+			errs = append(errs, release(rs[i]))
+		}
+		return errors.Join(errs...)
+	}
+
+	return Bracket(
+		func() ([]R, error) {
+			rs := make([]R, 0, len(acquires))
+			for _, acquire := range acquires {
+				r, err := acquire()
+				if err != nil {
+					// MUST NOT leak the resources acquired so far!
+					return nil, errors.Join(err, released(releaseAll(rs)))
+				}
+				rs = append(rs, r)
+			}
+			return rs, nil
+		},
+		releaseAll,
+		use,
+	)
+}
+
+// BracketPaginate is like [Bracket], but use is called for every page of a paginated source, e.g.
+// an API reached through a client. The pages are fetched with fetch, starting from the cursor
+// start. Each fetch returns the page, the cursor of the next one and whether there are more pages.
+// The paging stops at the first error of fetch or use.
+func BracketPaginate[R, P any](acquire func() (R, error), release func(R) error, fetch func(R, P) ([]byte, P, bool, error), start P, use func(page []byte) error) error {
+	return Bracket(acquire, release, func(r R) error {
+		for cursor, more := start, true; more; {
+			var (
+				page []byte
+				err  error
+			)
+			if page, cursor, more, err = fetch(r, cursor); err != nil {
+				return err
+			}
+			if err = use(page); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ProgressFunc receives the progress of a long operation, e.g. the number of copied bytes out of the
+// size of a file.
+type ProgressFunc func(done, total int64)
+
+// BracketProgress is like [Bracket], but use gets a reporter of its progress which forwards to
+// progress. A nil progress discards the reports.
+func BracketProgress[R any](acquire func() (R, error), release func(R) error, progress ProgressFunc, use func(R, func(done, total int64)) error) error {
+	if progress == nil {
+		progress = func(int64, int64) {}
+	}
+	return Bracket(acquire, release, func(r R) error { return use(r, progress) })
+}
@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+/* Helpers for testing code built on top of brago. */
+package bragotest
+
+import "sync"
+
+// Recorder records the order in which releases run. It is safe for concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	order []int
+}
+
+// RecordOrder returns a new, empty [Recorder].
+func RecordOrder() *Recorder {
+	return &Recorder{}
+}
+
+// Release returns a release function which records id when called.
+func (r *Recorder) Release(id int) func() error {
+	return func() error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.order = append(r.order, id)
+		return nil
+	}
+}
+
+// Order returns the ids of the releases in the order they ran.
+func (r *Recorder) Order() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int(nil), r.order...)
+}
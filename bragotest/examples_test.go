@@ -0,0 +1,24 @@
+package bragotest_test
+
+import (
+	"fmt"
+
+	"github.com/thelissimus/brago"
+	"github.com/thelissimus/brago/bragotest"
+)
+
+func ExampleRecordOrder() {
+	rec := bragotest.RecordOrder()
+	resource := func(id int) (func() (int, error), func(int) error) {
+		release := rec.Release(id)
+		return func() (int, error) { return id, nil }, func(int) error { return release() }
+	}
+
+	acquire1, release1 := resource(1)
+	acquire2, release2 := resource(2)
+	brago.Bracket(acquire1, release1, func(int) error {
+		return brago.Bracket(acquire2, release2, func(int) error { return nil })
+	})
+	fmt.Println(rec.Order())
+	// Output: [2 1]
+}
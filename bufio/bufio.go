@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+/* Wrappers of brago for stdlib bufio package. */
+package bufio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/thelissimus/brago"
+)
+
+// WithReader is a wrapper for [pkg/bufio.NewReader]. The underlying reader is left open, see
+// [WithReaderClose] to close it too.
+func WithReader(r io.Reader, use func(*bufio.Reader) error) error {
+	return use(bufio.NewReader(r))
+}
+
+// WithReaderClose is like [WithReader], but afterwards the underlying reader is closed if it
+// implements io.Closer.
+func WithReaderClose(r io.Reader, use func(*bufio.Reader) error) error {
+	return brago.Bracket(
+		func() (io.Reader, error) { return r, nil },
+		func(r io.Reader) error {
+			if c, ok := r.(io.Closer); ok {
+				return c.Close()
+			}
+			return nil
+		},
+		func(r io.Reader) error { return WithReader(r, use) },
+	)
+}
+
+// WithWriterDiscardOnError is a wrapper for [pkg/bufio.NewWriter]. If use succeeds the buffer is
+// flushed, otherwise the buffered data is discarded, so a failed use does not leave a partial
+// output behind. The data which was already flushed, explicitly or because the buffer got full,
+// cannot be taken back.
+func WithWriterDiscardOnError(w io.Writer, use func(*bufio.Writer) error) error {
+	bw := bufio.NewWriter(w)
+	if err := use(bw); err != nil {
+		bw.Reset(io.Discard)
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// AutoFlushWriter is a [pkg/bufio.Writer] which is flushed periodically by [WithAutoFlush]. Unlike
+// bufio.Writer, it is safe for concurrent use, so the periodic flushes do not race with the writes.
+type AutoFlushWriter struct {
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+// Write writes p into the buffer.
+func (w *AutoFlushWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+// Flush writes the buffered data to the underlying writer.
+func (w *AutoFlushWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Flush()
+}
+
+// WithAutoFlush buffers w with a buffer of the given size, which is flushed every interval while
+// use runs. Afterwards the periodic flushing is stopped and the buffer is flushed one last time. A
+// failed periodic flush makes the following writes and flushes fail with the same error. The
+// interval MUST be positive, otherwise an error is returned and use is not run.
+func WithAutoFlush(w io.Writer, size int, interval time.Duration, use func(*AutoFlushWriter) error) error {
+	type flusher struct {
+		w    *AutoFlushWriter
+		stop chan struct{}
+		done chan struct{}
+	}
+
+	return brago.Bracket(
+		func() (flusher, error) {
+			if interval <= 0 {
+				// time.NewTicker would panic on the goroutine, out of reach of the caller.
+				return flusher{}, fmt.Errorf("brago/bufio: non-positive flush interval %v", interval)
+			}
+
+			f := flusher{
+				w:    &AutoFlushWriter{w: bufio.NewWriterSize(w, size)},
+				stop: make(chan struct{}),
+				done: make(chan struct{}),
+			}
+
+			t := time.NewTicker(interval)
+			go func() {
+				defer t.Stop()
+				defer close(f.done)
+
+				for {
+					select {
+					case <-t.C:
+						f.w.Flush()
+					case <-f.stop:
+						return
+					}
+				}
+			}()
+
+			return f, nil
+		},
+		func(f flusher) error {
+			close(f.stop)
+			<-f.done
+			return f.w.Flush()
+		},
+		func(f flusher) error { return use(f.w) },
+	)
+}
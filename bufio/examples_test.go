@@ -0,0 +1,120 @@
+package bufio_test
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	bbufio "github.com/thelissimus/brago/bufio"
+)
+
+type closingReader struct {
+	io.Reader
+}
+
+func (closingReader) Close() error {
+	fmt.Println("closed")
+	return nil
+}
+
+func ExampleWithReader() {
+	r := closingReader{strings.NewReader("first line\nsecond line\n")}
+	err := bbufio.WithReader(r, func(br *bufio.Reader) error {
+		line, err := br.ReadString('\n')
+		fmt.Print(line)
+		return err
+	})
+	fmt.Println(err)
+	// Output:
+	// first line
+	// <nil>
+}
+
+func ExampleWithReaderClose() {
+	r := closingReader{strings.NewReader("first line\nsecond line\n")}
+	err := bbufio.WithReaderClose(r, func(br *bufio.Reader) error {
+		line, err := br.ReadString('\n')
+		fmt.Print(line)
+		return err
+	})
+	fmt.Println(err)
+	// Output:
+	// first line
+	// closed
+	// <nil>
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func ExampleWithAutoFlush() {
+	var out syncBuffer
+	err := bbufio.WithAutoFlush(&out, 4096, 5*time.Millisecond, func(w *bbufio.AutoFlushWriter) error {
+		io.WriteString(w, "streamed\n")
+		for out.String() == "" {
+			time.Sleep(time.Millisecond)
+		}
+		fmt.Print("flushed during use: ", out.String())
+		_, err := io.WriteString(w, "last\n")
+		return err
+	})
+	fmt.Println(err)
+	fmt.Print(out.String())
+
+	err = bbufio.WithAutoFlush(failingWriter{}, 4096, time.Hour, func(w *bbufio.AutoFlushWriter) error {
+		_, err := io.WriteString(w, "lost")
+		return err
+	})
+	fmt.Println(err)
+
+	err = bbufio.WithAutoFlush(&out, 4096, 0, func(w *bbufio.AutoFlushWriter) error {
+		fmt.Println("not reached")
+		return nil
+	})
+	fmt.Println(err)
+	// Output:
+	// flushed during use: streamed
+	// <nil>
+	// streamed
+	// last
+	// broken pipe
+	// brago/bufio: non-positive flush interval 0s
+}
+
+func ExampleWithWriterDiscardOnError() {
+	for _, useErr := range []error{nil, errors.New("invalid record")} {
+		var out strings.Builder
+		err := bbufio.WithWriterDiscardOnError(&out, func(w *bufio.Writer) error {
+			w.WriteString("header\n")
+			return useErr
+		})
+		fmt.Printf("%q %v\n", out.String(), err)
+	}
+	// Output:
+	// "header\n" <nil>
+	// "" invalid record
+}
@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+/* Brackets of brago for pooled bytes.Buffer values. */
+package bufpool
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/thelissimus/brago"
+)
+
+var pool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// WithBuffer takes an empty buffer from a pool and passes it to use. Afterwards the buffer is reset
+// and put back into the pool, so it MUST NOT be retained after use returns.
+func WithBuffer(use func(*bytes.Buffer) error) error {
+	return brago.Bracket(
+		func() (*bytes.Buffer, error) {
+			buf := pool.Get().(*bytes.Buffer)
+			buf.Reset()
+			return buf, nil
+		},
+		func(buf *bytes.Buffer) error {
+			buf.Reset()
+			pool.Put(buf)
+			return nil
+		},
+		use,
+	)
+}
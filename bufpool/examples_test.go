@@ -0,0 +1,25 @@
+package bufpool_test
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/thelissimus/brago/bufpool"
+)
+
+func ExampleWithBuffer() {
+	seen := map[*bytes.Buffer]bool{}
+	reused, leftover := false, false
+	for i := 0; i < 100; i++ {
+		bufpool.WithBuffer(func(buf *bytes.Buffer) error {
+			reused = reused || seen[buf]
+			leftover = leftover || buf.Len() > 0
+			seen[buf] = true
+
+			fmt.Fprintf(buf, "message %d", i)
+			return nil
+		})
+	}
+	fmt.Println(reused, leftover)
+	// Output: true false
+}
@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package brago
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by [CircuitBracket.Use] while the circuit is open.
+var ErrCircuitOpen = errors.New("brago: circuit is open")
+
+// CircuitBracket is a [Bracket] guarded by a circuit breaker. After threshold consecutive failures
+// of acquire the circuit opens and Use fails with [ErrCircuitOpen] without trying to acquire the
+// resource. Once the cooldown passes, a single Use is let through to probe the resource: if the
+// acquisition succeeds the circuit closes, otherwise it opens again. It is safe for concurrent use.
+type CircuitBracket[R any] struct {
+	acquire   func() (R, error)
+	release   func(R) error
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuit is used to create the [CircuitBracket] of the resource.
+func NewCircuit[R any](acquire func() (R, error), release func(R) error, threshold int, cooldown time.Duration) *CircuitBracket[R] {
+	return &CircuitBracket[R]{
+		acquire:   acquire,
+		release:   release,
+		threshold: max(threshold, 1),
+		cooldown:  cooldown,
+	}
+}
+
+// Use is like [Bracket] with the acquire and release of the circuit.
+func (c *CircuitBracket[R]) Use(use func(R) error) error {
+	if err := c.allow(); err != nil {
+		return err
+	}
+	return Bracket(c.tryAcquire, c.release, use)
+}
+
+func (c *CircuitBracket[R]) allow() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.failures < c.threshold {
+		return nil
+	}
+	if c.probing || time.Since(c.openedAt) < c.cooldown {
+		return ErrCircuitOpen
+	}
+
+	c.probing = true
+	return nil
+}
+
+func (c *CircuitBracket[R]) tryAcquire() (R, error) {
+	r, err := c.acquire()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.probing = false
+	if err != nil {
+		c.failures++
+		if c.failures >= c.threshold {
+			c.openedAt = time.Now()
+		}
+		return r, err
+	}
+
+	c.failures = 0
+	return r, nil
+}
@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package brago
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BracketSpan is like [Bracket], but the whole bracket is wrapped in a span of a tracer. The span
+// is started with startSpan, which returns the context of the span and a function ending it with
+// the final error. The context of the span is passed to acquire and use.
+func BracketSpan[R any](
+	ctx context.Context,
+	name string,
+	startSpan func(context.Context, string) (context.Context, func(error)),
+	acquire func(context.Context) (R, error),
+	release func(R) error,
+	use func(context.Context, R) error,
+) error {
+	ctx, end := startSpan(ctx, name)
+	err := Bracket(
+		func() (R, error) { return acquire(ctx) },
+		release,
+		func(r R) error { return use(ctx, r) },
+	)
+	end(err)
+	return err
+}
+
+// acquireTimeoutAttempts bounds the attempts of [BracketAcquireWithTimeout].
+const acquireTimeoutAttempts = 5
+
+// BracketAcquireWithTimeout is like [Bracket], but every attempt of acquire gets its own context
+// which times out after perAttempt. An attempt which fails because of its timeout is retried after
+// a pause, up to 5 attempts in total and until ctx is done. The pause starts at a tenth of
+// perAttempt and doubles with every attempt. The perAttempt MUST be positive, otherwise an error is
+// returned without acquiring anything.
+func BracketAcquireWithTimeout[R any](ctx context.Context, perAttempt time.Duration, acquire func(context.Context) (R, error), release func(R) error, use func(R) error) error {
+	if perAttempt <= 0 {
+		return fmt.Errorf("brago: non-positive acquire timeout %v", perAttempt)
+	}
+
+	return Bracket(
+		func() (R, error) {
+			pause := perAttempt / 10
+			for i := 1; ; i++ {
+				actx, cancel := context.WithTimeout(ctx, perAttempt)
+				r, err := acquire(actx)
+				cancel()
+
+				if err == nil || i == acquireTimeoutAttempts || ctx.Err() != nil || !errors.Is(err, context.DeadlineExceeded) {
+					return r, err
+				}
+				if serr := sleepContext(ctx, pause); serr != nil {
+					return r, errors.Join(err, serr)
+				}
+				pause *= 2
+			}
+		},
+		release,
+		use,
+	)
+}
+
+// BracketGraceful is like [Bracket], but the resource is released in two steps. First graceful
+// shuts it down with a context which times out after timeout, and graceful is expected to honor it,
+// like [pkg/net/http.Server.Shutdown] does. If graceful fails or times out, force shuts the
+// resource down, e.g. with Kill or Close.
+func BracketGraceful[R any](acquire func() (R, error), graceful func(R, context.Context) error, force func(R) error, timeout time.Duration, use func(R) error) error {
+	return Bracket(
+		acquire,
+		func(r R) error {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			if err := graceful(r, ctx); err != nil {
+				return errors.Join(err, force(r))
+			}
+			return nil
+		},
+		use,
+	)
+}
+
+// BracketUseDeadline is like [Bracket], but the resource is held by use for at most d. If use does
+// not finish in time, the resource is released while use is still running and
+// [pkg/context.DeadlineExceeded] is returned. The release MUST be able to interrupt use, e.g. by
+// closing the connection use is blocked on.
+func BracketUseDeadline[R any](d time.Duration, acquire func() (R, error), release func(R) error, use func(R) error) error {
+	return Bracket(acquire, release, func(r R) error {
+		done := make(chan error, 1)
+		go func() { done <- use(r) }()
+
+		t := time.NewTimer(d)
+		defer t.Stop()
+
+		select {
+		case err := <-done:
+			return err
+		case <-t.C:
+			return context.DeadlineExceeded
+		}
+	})
+}
+
+// BracketProvide is like [Bracket], but the resource is stored in the context passed to use under
+// key, like a request-scoped dependency. The code called by use retrieves it with [Provided].
+func BracketProvide[R any](ctx context.Context, key any, acquire func() (R, error), release func(R) error, use func(context.Context) error) error {
+	return Bracket(acquire, release, func(r R) error {
+		return use(context.WithValue(ctx, key, r))
+	})
+}
+
+// Provided returns the resource stored in ctx under key by [BracketProvide]. It reports false if
+// there is none or it is not of type R.
+func Provided[R any](ctx context.Context, key any) (R, bool) {
+	r, ok := ctx.Value(key).(R)
+	return r, ok
+}
+
+// BracketContextHandle acquires the resource with a child context of parent, which lives as long
+// as the resource, and hands them over to the caller, like [WithHandoff]. The returned cancel only
+// cancels the context. The returned cleanup cancels the context and then releases the resource. It
+// does so only once, later calls return the same error.
+func BracketContextHandle[R any](parent context.Context, acquire func(context.Context) (R, error), release func(R) error) (R, context.Context, context.CancelFunc, func() error, error) {
+	ctx, cancel := context.WithCancel(parent)
+	r, err := acquire(ctx)
+	if err != nil {
+		cancel()
+		return Zero[R](), nil, nil, nil, err
+	}
+
+	cleanup := sync.OnceValue(func() error {
+		cancel()
+		return released(release(r))
+	})
+	return r, ctx, cancel, cleanup, nil
+}
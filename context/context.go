@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+/* Wrappers of brago for stdlib context package. */
+package context
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/thelissimus/brago"
+)
+
+type scopeKey struct{}
+
+// WithScope returns a copy of ctx carrying a new [brago.Scope]. The caller must close the scope.
+func WithScope(ctx context.Context) (context.Context, *brago.Scope) {
+	s := &brago.Scope{}
+	return context.WithValue(ctx, scopeKey{}, s), s
+}
+
+// ScopeFrom returns the innermost [brago.Scope] carried by ctx, or nil if there is none.
+func ScopeFrom(ctx context.Context) *brago.Scope {
+	s, _ := ctx.Value(scopeKey{}).(*brago.Scope)
+	return s
+}
+
+// RunScopeContext runs fn with a context carrying a new [brago.Scope], which is closed afterwards.
+func RunScopeContext(ctx context.Context, fn func(context.Context) error) error {
+	ctx, s := WithScope(ctx)
+	return brago.Bracket(
+		func() (*brago.Scope, error) { return s, nil },
+		(*brago.Scope).Close,
+		func(*brago.Scope) error { return fn(ctx) },
+	)
+}
+
+// WithSignals is a wrapper for [pkg/os/signal.NotifyContext]. The context passed to use is
+// cancelled when one of the signals arrives. Afterwards the signals are no longer relayed.
+func WithSignals(parent context.Context, use func(context.Context) error, signals ...os.Signal) error {
+	type notify struct {
+		ctx  context.Context
+		stop context.CancelFunc
+	}
+
+	return brago.Bracket(
+		func() (notify, error) {
+			ctx, stop := signal.NotifyContext(parent, signals...)
+			return notify{ctx, stop}, nil
+		},
+		func(n notify) error {
+			n.stop()
+			return nil
+		},
+		func(n notify) error { return use(n.ctx) },
+	)
+}
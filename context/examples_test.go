@@ -0,0 +1,40 @@
+package context_test
+
+import (
+	"context"
+	"fmt"
+
+	bcontext "github.com/thelissimus/brago/context"
+)
+
+func open(ctx context.Context, name string) string {
+	fmt.Println("open", name)
+	bcontext.ScopeFrom(ctx).Defer(func() error {
+		fmt.Println("close", name)
+		return nil
+	})
+	return name
+}
+
+func handle(ctx context.Context) error {
+	open(ctx, "db")
+	return query(ctx)
+}
+
+func query(ctx context.Context) error {
+	open(ctx, "cursor")
+	fmt.Println("query")
+	return nil
+}
+
+func ExampleRunScopeContext() {
+	err := bcontext.RunScopeContext(context.Background(), handle)
+	fmt.Println(err)
+	// Output:
+	// open db
+	// open cursor
+	// query
+	// close cursor
+	// close db
+	// <nil>
+}
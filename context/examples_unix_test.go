@@ -0,0 +1,24 @@
+//go:build unix
+
+package context_test
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+
+	bcontext "github.com/thelissimus/brago/context"
+)
+
+func ExampleWithSignals() {
+	err := bcontext.WithSignals(context.Background(), func(ctx context.Context) error {
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+		<-ctx.Done()
+		fmt.Println("shutting down:", ctx.Err())
+		return nil
+	}, syscall.SIGUSR1)
+	fmt.Println(err)
+	// Output:
+	// shutting down: context canceled
+	// <nil>
+}
@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+/* Wrappers of brago for stdlib encoding/csv package. */
+package csv
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/thelissimus/brago"
+)
+
+// WithWriter is a wrapper for [pkg/encoding/csv.NewWriter]. Afterwards the writer is flushed and
+// its error is checked. The underlying writer is left open, see [WithWriterClose] to close it too.
+func WithWriter(w io.Writer, use func(*csv.Writer) error) error {
+	return brago.Bracket(
+		func() (*csv.Writer, error) { return csv.NewWriter(w), nil },
+		func(cw *csv.Writer) error {
+			cw.Flush()
+			return cw.Error()
+		},
+		use,
+	)
+}
+
+// WithWriterClose is like [WithWriter], but afterwards the underlying writer is closed if it
+// implements io.Closer.
+func WithWriterClose(w io.Writer, use func(*csv.Writer) error) error {
+	return brago.Bracket(
+		func() (io.Writer, error) { return w, nil },
+		func(w io.Writer) error {
+			if c, ok := w.(io.Closer); ok {
+				return c.Close()
+			}
+			return nil
+		},
+		func(w io.Writer) error { return WithWriter(w, use) },
+	)
+}
@@ -0,0 +1,52 @@
+package csv_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	bcsv "github.com/thelissimus/brago/csv"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func (failingWriter) Close() error {
+	fmt.Println("closed")
+	return nil
+}
+
+func ExampleWithWriter() {
+	var sb strings.Builder
+	err := bcsv.WithWriter(&sb, func(cw *csv.Writer) error {
+		return cw.WriteAll([][]string{{"name", "age"}, {"gopher", "16"}})
+	})
+	fmt.Print(sb.String())
+	fmt.Println(err)
+
+	err = bcsv.WithWriter(os.Stdout, func(cw *csv.Writer) error {
+		return cw.Write([]string{"buffered", "row"})
+	})
+	fmt.Println(err)
+	// Output:
+	// name,age
+	// gopher,16
+	// <nil>
+	// buffered,row
+	// <nil>
+}
+
+func ExampleWithWriterClose() {
+	err := bcsv.WithWriterClose(failingWriter{}, func(cw *csv.Writer) error {
+		return cw.Write([]string{"lost", "row"})
+	})
+	fmt.Println(err)
+	// Output:
+	// closed
+	// disk full
+}
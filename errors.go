@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package brago
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// CombinePolicy decides which error a bracket returns when both use and release fail.
+type CombinePolicy int32
+
+const (
+	// JoinAll returns both errors joined with errors.Join.
+	JoinAll CombinePolicy = iota
+	// UseErrorWins returns the error of use.
+	UseErrorWins
+	// ReleaseErrorWins returns the error of release.
+	ReleaseErrorWins
+	// FirstNonNil returns the error which occurred first, which is the error of use.
+	FirstNonNil
+)
+
+// Combine combines the errors of use and release according to the policy. If one of them is nil,
+// the other one is returned regardless of the policy.
+func (p CombinePolicy) Combine(useErr, releaseErr error) error {
+	if useErr == nil {
+		return releaseErr
+	}
+	if releaseErr == nil {
+		return useErr
+	}
+
+	switch p {
+	case UseErrorWins, FirstNonNil:
+		return useErr
+	case ReleaseErrorWins:
+		return releaseErr
+	default:
+		return errors.Join(useErr, releaseErr)
+	}
+}
+
+var defaultCombinePolicy atomic.Int32
+
+// DefaultCombinePolicy returns the policy [Bracket] uses to combine the errors. It is [JoinAll]
+// unless changed with [SetDefaultCombinePolicy].
+func DefaultCombinePolicy() CombinePolicy {
+	return CombinePolicy(defaultCombinePolicy.Load())
+}
+
+// SetDefaultCombinePolicy changes the policy [Bracket] uses to combine the errors. It is safe to
+// call concurrently with running brackets, each of which reads the policy once.
+func SetDefaultCombinePolicy(p CombinePolicy) {
+	defaultCombinePolicy.Store(int32(p))
+}
+
+// ReleaseError is the error of a release returned by the brackets of the package. It makes the
+// failures of releases distinguishable from the failures of uses, see [IsReleaseFailure] and
+// [UseError].
+type ReleaseError struct {
+	Err error
+}
+
+func (e *ReleaseError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ReleaseError) Unwrap() error {
+	return e.Err
+}
+
+// IsReleaseFailure reports whether the error returned by a bracket contains a failure of a release.
+func IsReleaseFailure(err error) bool {
+	var re *ReleaseError
+	return errors.As(err, &re)
+}
+
+// UseError extracts the error of use from the error returned by a bracket, leaving out the errors
+// of releases. It returns nil if only releases failed.
+func UseError(err error) error {
+	switch e := err.(type) {
+	case *ReleaseError:
+		return nil
+	case interface{ Unwrap() []error }:
+		var errs []error
+		for _, err := range e.Unwrap() {
+			// The errors of use may be joined with releases of their own, e.g. by BracketStaged.
+			if err = UseError(err); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if len(errs) == 1 {
+			return errs[0]
+		}
+		return errors.Join(errs...)
+	default:
+		return err
+	}
+}
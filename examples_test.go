@@ -1,7 +1,19 @@
 package brago_test
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net"
 	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/thelissimus/brago"
 )
@@ -32,3 +44,1415 @@ func ExampleWithResource() {
 		},
 	)
 }
+
+type countingName struct{ calls int }
+
+func (n *countingName) String() string {
+	n.calls++
+	return "conn"
+}
+
+func ExampleBracketNamed() {
+	name := &countingName{}
+	brago.BracketNamed(
+		name,
+		func() (int, error) { return 1, nil },
+		func(int) error { return nil },
+		func(int) error { return nil },
+	)
+	fmt.Println(name.calls)
+
+	err := brago.BracketNamed(
+		name,
+		func() (int, error) { return 1, nil },
+		func(int) error { return errors.New("close failed") },
+		func(int) error { return nil },
+	)
+	fmt.Println(err)
+	// Output:
+	// 0
+	// conn: close failed
+}
+
+func ExampleBracketRetryBackoff() {
+	var delays []time.Duration
+	cfg := brago.RetryConfig{
+		InitialDelay: 10 * time.Millisecond,
+		MaxDelay:     50 * time.Millisecond,
+		Multiplier:   2,
+		MaxAttempts:  5,
+		Sleep: func(_ context.Context, d time.Duration) error {
+			delays = append(delays, d)
+			return nil
+		},
+	}
+
+	attempts := 0
+	err := brago.BracketRetryBackoff(
+		context.Background(),
+		cfg,
+		func() (int, error) {
+			attempts++
+			if attempts < 5 {
+				return 0, errors.New("unavailable")
+			}
+			return attempts, nil
+		},
+		func(int) error { return nil },
+		func(r int) error {
+			fmt.Println("acquired on attempt", r)
+			return nil
+		},
+	)
+	fmt.Println(delays, err)
+
+	// The cap applies to the jittered delays too.
+	delays = nil
+	cfg.InitialDelay, cfg.Jitter = 100*time.Millisecond, 0.5
+	brago.BracketRetryBackoff(
+		context.Background(),
+		cfg,
+		func() (int, error) { return 0, errors.New("unavailable") },
+		func(int) error { return nil },
+		func(int) error { return nil },
+	)
+	fmt.Println(len(delays), slices.Max(delays) <= cfg.MaxDelay)
+	// Output:
+	// acquired on attempt 5
+	// [10ms 20ms 40ms 50ms] <nil>
+	// 4 true
+}
+
+type countingCloser struct{ closes int }
+
+func (c *countingCloser) Close() error {
+	c.closes++
+	return nil
+}
+
+// chanCloser closes the channel, so closing it twice panics.
+type chanCloser chan struct{}
+
+func (c chanCloser) Close() error {
+	close(c)
+	return nil
+}
+
+// failingCloser fails every Close with the number of the call.
+type failingCloser struct{ closes int }
+
+func (c *failingCloser) Close() error {
+	c.closes++
+	return fmt.Errorf("close %d failed", c.closes)
+}
+
+func ExampleWithResourceSafe() {
+	err := brago.WithResourceSafe(
+		func() (chanCloser, error) { return make(chanCloser), nil },
+		func(c chanCloser, close func() error) error { return close() },
+	)
+	fmt.Println(err)
+
+	c := &failingCloser{}
+	err = brago.WithResourceSafe(
+		func() (*failingCloser, error) { return c, nil },
+		func(c *failingCloser, close func() error) error {
+			close() // the error is reported by the bracket
+			return nil
+		},
+	)
+	fmt.Println(err, c.closes)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	err = brago.WithResourceSafe(
+		func() (net.Listener, error) { return l, nil },
+		func(l net.Listener, _ func() error) error { return l.Close() },
+	)
+	fmt.Println(err)
+	// Output:
+	// <nil>
+	// close 1 failed 1
+	// <nil>
+}
+
+func ExampleAcquire2() {
+	c, cleanup, err := brago.Acquire2(func() (*countingCloser, error) { return &countingCloser{}, nil })
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	cleanup()
+	cleanup()
+	fmt.Println(c.closes)
+	// Output: 1
+}
+
+func ExampleAndThen() {
+	validate := func(f *os.File) error {
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if info.Size() != 0 {
+			return errors.New("file is not empty")
+		}
+		return nil
+	}
+	write := func(f *os.File) error {
+		_, err := f.WriteString("hello")
+		return err
+	}
+
+	f, err := os.CreateTemp("", "brago-example")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	name := f.Name()
+	f.Close()
+	defer os.Remove(name)
+
+	open := func() (*os.File, error) { return os.OpenFile(name, os.O_RDWR, 0) }
+	fmt.Println(brago.WithResource(open, brago.AndThen(validate, write)))
+	fmt.Println(brago.WithResource(open, brago.AndThen(validate, write)))
+	// Output:
+	// <nil>
+	// file is not empty
+}
+
+func ExampleBracketEither() {
+	err := brago.BracketEither(
+		func() (string, error) { return "", errors.New("unix socket unavailable") },
+		func() (string, error) { return "tcp", nil },
+		func(r string) error {
+			fmt.Println("released", r)
+			return nil
+		},
+		func(r string) error { return errors.New("use failed") },
+	)
+	fmt.Println(err)
+	// Output:
+	// released tcp
+	// use failed
+}
+
+func ExampleBracketValidate() {
+	err := brago.BracketValidate(
+		func() (string, error) { return "conn", nil },
+		func(string) error { return errors.New("handshake failed") },
+		func(r string) error {
+			fmt.Println("released", r)
+			return nil
+		},
+		func(r string) error {
+			fmt.Println("used", r)
+			return nil
+		},
+	)
+	fmt.Println(err)
+	// Output:
+	// released conn
+	// handshake failed
+}
+
+func ExampleBracketSink() {
+	var results []int
+	for _, fail := range []bool{false, true, false} {
+		brago.BracketSink(
+			func() (int, error) { return len(results), nil },
+			func(int) error { return nil },
+			func(r int) (int, error) {
+				if fail {
+					return 0, errors.New("use failed")
+				}
+				return r * 10, nil
+			},
+			func(a int) { results = append(results, a) },
+		)
+	}
+	fmt.Println(results)
+	// Output: [0 10]
+}
+
+func ExampleBracketMulti() {
+	step := func(name string, err error) func(string) error {
+		return func(string) error {
+			fmt.Println(name)
+			return err
+		}
+	}
+
+	err := brago.BracketMulti(
+		func() (string, error) { return "writer", nil },
+		func(string) error { return nil },
+		step("close", nil),
+		step("flush", errors.New("flush failed")),
+	)
+	fmt.Println(err)
+	// Output:
+	// flush
+	// close
+	// flush failed
+}
+
+type spanKey struct{}
+
+func ExampleBracketSpan() {
+	startSpan := func(ctx context.Context, name string) (context.Context, func(error)) {
+		fmt.Println("start", name)
+		return context.WithValue(ctx, spanKey{}, name), func(err error) { fmt.Println("end", name, err) }
+	}
+
+	err := brago.BracketSpan(
+		context.Background(),
+		"query",
+		startSpan,
+		func(ctx context.Context) (string, error) { return "conn", nil },
+		func(string) error { return errors.New("close failed") },
+		func(ctx context.Context, r string) error {
+			fmt.Println("use in span", ctx.Value(spanKey{}))
+			return errors.New("query failed")
+		},
+	)
+	fmt.Println(err != nil)
+	// Output:
+	// start query
+	// use in span query
+	// end query query failed
+	// close failed
+	// true
+}
+
+func ExampleBracketAcquireWithTimeout() {
+	attempts := 0
+	err := brago.BracketAcquireWithTimeout(
+		context.Background(),
+		10*time.Millisecond,
+		func(ctx context.Context) (int, error) {
+			attempts++
+			if attempts < 3 {
+				<-ctx.Done()
+				fmt.Println("attempt", attempts, ctx.Err())
+				return 0, ctx.Err()
+			}
+			return attempts, nil
+		},
+		func(int) error { return nil },
+		func(r int) error {
+			fmt.Println("acquired on attempt", r)
+			return nil
+		},
+	)
+	fmt.Println(err)
+
+	// An acquire which keeps timing out is given up on.
+	attempts = 0
+	err = brago.BracketAcquireWithTimeout(
+		context.Background(),
+		time.Millisecond,
+		func(ctx context.Context) (int, error) {
+			attempts++
+			return 0, fmt.Errorf("dial: %w", context.DeadlineExceeded)
+		},
+		func(int) error { return nil },
+		func(int) error { return nil },
+	)
+	fmt.Println(attempts, err)
+
+	err = brago.BracketAcquireWithTimeout(
+		context.Background(),
+		0,
+		func(ctx context.Context) (int, error) { return 0, nil },
+		func(int) error { return nil },
+		func(int) error { return nil },
+	)
+	fmt.Println(err)
+	// Output:
+	// attempt 1 context deadline exceeded
+	// attempt 2 context deadline exceeded
+	// acquired on attempt 3
+	// <nil>
+	// 5 dial: context deadline exceeded
+	// brago: non-positive acquire timeout 0s
+}
+
+type dbKey struct{}
+
+func ExampleBracketProvide() {
+	handle := func(ctx context.Context) error {
+		db, ok := brago.Provided[*strings.Builder](ctx, dbKey{})
+		if !ok {
+			return errors.New("no database")
+		}
+		db.WriteString("query")
+		return nil
+	}
+
+	err := brago.BracketProvide(
+		context.Background(),
+		dbKey{},
+		func() (*strings.Builder, error) { return &strings.Builder{}, nil },
+		func(db *strings.Builder) error {
+			fmt.Println("released after", db.String())
+			return nil
+		},
+		handle,
+	)
+	fmt.Println(err)
+
+	_, ok := brago.Provided[*strings.Builder](context.Background(), dbKey{})
+	fmt.Println(ok)
+	// Output:
+	// released after query
+	// <nil>
+	// false
+}
+
+func ExampleBracketContextHandle() {
+	var ctx context.Context
+	r, ctx, _, cleanup, err := brago.BracketContextHandle(
+		context.Background(),
+		func(ctx context.Context) (string, error) { return "conn", nil },
+		func(r string) error {
+			fmt.Println("released", r, "after", ctx.Err())
+			return nil
+		},
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(r, ctx.Err())
+	fmt.Println(cleanup())
+	fmt.Println(cleanup())
+	fmt.Println(ctx.Err())
+	// Output:
+	// conn <nil>
+	// released conn after context canceled
+	// <nil>
+	// <nil>
+	// context canceled
+}
+
+func ExampleBracketGraceful() {
+	err := brago.BracketGraceful(
+		func() (string, error) { return "server", nil },
+		func(r string, ctx context.Context) error {
+			<-ctx.Done() // the connections are never drained
+			return ctx.Err()
+		},
+		func(r string) error {
+			fmt.Println("killed", r)
+			return nil
+		},
+		10*time.Millisecond,
+		func(string) error { return nil },
+	)
+	fmt.Println(err)
+	// Output:
+	// killed server
+	// context deadline exceeded
+}
+
+func ExampleWithCloserMap() {
+	closers := map[string]*countingCloser{"primary": {}, "replica": {}}
+	err := brago.WithCloserMap(
+		func() (map[string]io.Closer, error) {
+			m := make(map[string]io.Closer, len(closers))
+			for k, c := range closers {
+				m[k] = c
+			}
+			return m, nil
+		},
+		func(map[string]io.Closer) error { return nil },
+	)
+	fmt.Println(err, closers["primary"].closes, closers["replica"].closes)
+	// Output: <nil> 1 1
+}
+
+func ExampleBracketAccumulate() {
+	lines, err := brago.BracketAccumulate(
+		func() ([]string, error) { return []string{"a", "b", "", "d"}, nil },
+		func([]string) error { return nil },
+		func(r []string, acc *[]string) error {
+			for _, line := range r {
+				if line == "" {
+					return errors.New("malformed line")
+				}
+				*acc = append(*acc, line)
+			}
+			return nil
+		},
+	)
+	fmt.Println(lines, err)
+	// Output: [a b] malformed line
+}
+
+func ExampleGo() {
+	var mu sync.Mutex
+	released := make([]bool, 3)
+
+	waits := make([]func() error, len(released))
+	for i := range released {
+		waits[i] = brago.Go(
+			func() (int, error) { return i, nil },
+			func(r int) error {
+				mu.Lock()
+				defer mu.Unlock()
+				released[r] = true
+				return nil
+			},
+			func(r int) error {
+				if r == 1 {
+					return errors.New("task 1 failed")
+				}
+				return nil
+			},
+		)
+	}
+
+	for _, wait := range waits {
+		fmt.Println(wait())
+	}
+	fmt.Println(released)
+	// Output:
+	// <nil>
+	// task 1 failed
+	// <nil>
+	// [true true true]
+}
+
+func ExampleBracketIf() {
+	for _, enabled := range []bool{false, true} {
+		err := brago.BracketIf(
+			func() bool { return enabled },
+			func() (string, error) {
+				fmt.Println("acquired")
+				return "cache", nil
+			},
+			func(string) error { return nil },
+			func(string) error { return nil },
+		)
+		fmt.Println(enabled, err)
+	}
+	// Output:
+	// false <nil>
+	// acquired
+	// true <nil>
+}
+
+func ExampleLease() {
+	released := make(chan int)
+	acquired := 0
+	lease := brago.NewLease(
+		func() (int, error) {
+			acquired++
+			fmt.Println("acquired", acquired)
+			return acquired, nil
+		},
+		func(r int) error {
+			released <- r
+			return nil
+		},
+		10*time.Millisecond,
+	)
+
+	for i := 0; i < 2; i++ {
+		lease.Use(func(r int) error {
+			fmt.Println("used", r)
+			return nil
+		})
+	}
+	fmt.Println("released", <-released)
+
+	lease.Use(func(r int) error {
+		fmt.Println("used", r)
+		return nil
+	})
+	fmt.Println("released", <-released)
+	fmt.Println(lease.Close())
+	// Output:
+	// acquired 1
+	// used 1
+	// used 1
+	// released 1
+	// acquired 2
+	// used 2
+	// released 2
+	// <nil>
+}
+
+func ExampleWithChannel() {
+	var recv <-chan int
+	err := brago.WithChannel(3, func(send chan<- int, r <-chan int) error {
+		recv = r
+		send <- 1
+		send <- 2
+		send <- 3
+		fmt.Println("received", <-r)
+		return nil
+	})
+	fmt.Println(err)
+
+	for v := range recv {
+		fmt.Println("left", v)
+	}
+	fmt.Println("closed")
+	// Output:
+	// received 1
+	// <nil>
+	// left 2
+	// left 3
+	// closed
+}
+
+func ExampleBracketPost() {
+	post := func(err error) { fmt.Println("observed", err) }
+	for _, useErr := range []error{nil, errors.New("use failed")} {
+		err := brago.BracketPost(
+			func() (int, error) { return 1, nil },
+			func(int) error { return errors.New("release failed") },
+			post,
+			func(int) error { return useErr },
+		)
+		fmt.Println("returned", err)
+	}
+	// Output:
+	// observed release failed
+	// returned release failed
+	// observed use failed
+	// release failed
+	// returned use failed
+	// release failed
+}
+
+func ExampleBracketStaged() {
+	stage := func(name string, err, teardownErr error) func(string) (func() error, error) {
+		return func(string) (func() error, error) {
+			if err != nil {
+				return nil, err
+			}
+			fmt.Println("set up", name)
+			return func() error {
+				fmt.Println("tear down", name)
+				return teardownErr
+			}, nil
+		}
+	}
+
+	err := brago.BracketStaged(
+		[]func(string) (func() error, error){
+			stage("authenticate", nil, nil),
+			stage("subscribe", errors.New("subscribe failed"), nil),
+			stage("stream", nil, nil),
+		},
+		func() (string, error) { return "conn", nil },
+		func(r string) error {
+			fmt.Println("closed", r)
+			return nil
+		},
+		func(string) error {
+			fmt.Println("used")
+			return nil
+		},
+	)
+	fmt.Println(err)
+
+	// A failed teardown is a failure of a release.
+	brago.OnReleaseError = func(err error) { fmt.Println("reported:", err) }
+	defer func() { brago.OnReleaseError = nil }()
+
+	err = brago.BracketStaged(
+		[]func(string) (func() error, error){
+			stage("subscribe", nil, errors.New("unsubscribe failed")),
+		},
+		func() (string, error) { return "conn", nil },
+		func(r string) error { return nil },
+		func(string) error { return nil },
+	)
+	fmt.Println(brago.IsReleaseFailure(err), brago.UseError(err))
+	// Output:
+	// set up authenticate
+	// tear down authenticate
+	// closed conn
+	// subscribe failed
+	// set up subscribe
+	// tear down subscribe
+	// reported: unsubscribe failed
+	// true <nil>
+}
+
+func ExampleCircuitBracket() {
+	down := true
+	circuit := brago.NewCircuit(
+		func() (string, error) {
+			if down {
+				return "", errors.New("connection refused")
+			}
+			return "conn", nil
+		},
+		func(string) error { return nil },
+		2,
+		20*time.Millisecond,
+	)
+	use := func(string) error { return nil }
+
+	fmt.Println(circuit.Use(use))
+	fmt.Println(circuit.Use(use))
+	fmt.Println(circuit.Use(use))
+
+	down = false
+	time.Sleep(30 * time.Millisecond)
+	fmt.Println(circuit.Use(use))
+	fmt.Println(circuit.Use(use))
+	// Output:
+	// connection refused
+	// connection refused
+	// brago: circuit is open
+	// <nil>
+	// <nil>
+}
+
+func ExampleSingleflightBracket() {
+	var acquires, releases atomic.Int32
+	sf := brago.NewSingleflight(
+		func(key string) (string, error) {
+			acquires.Add(1)
+			return "conn to " + key, nil
+		},
+		func(string) error {
+			releases.Add(1)
+			return nil
+		},
+	)
+
+	const n = 5
+	var inside, wg sync.WaitGroup
+	inside.Add(n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sf.Use("db", func(conn string) error {
+				// All of the uses hold the resource at the same time.
+				inside.Done()
+				inside.Wait()
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	fmt.Println(acquires.Load(), releases.Load())
+
+	sf.Use("db", func(string) error { return nil })
+	fmt.Println(acquires.Load(), releases.Load())
+	// Output:
+	// 1 1
+	// 2 2
+}
+
+func ExampleFromCloser() {
+	var file *os.File
+	lines := brago.Map(
+		brago.FromCloser(func() (*os.File, error) {
+			f, err := os.Open("LICENSE")
+			file = f
+			return f, err
+		}),
+		func(f *os.File) *bufio.Reader { return bufio.NewReader(f) },
+	)
+
+	err := lines(func(r *bufio.Reader) error {
+		line, err := r.ReadString('\n')
+		fmt.Print(line)
+		return err
+	})
+	fmt.Println(err, errors.Is(file.Close(), os.ErrClosed))
+	// Output:
+	// BSD 3-Clause License
+	// <nil> true
+}
+
+func ExampleBracketUseDeadline() {
+	interrupted := make(chan struct{})
+	err := brago.BracketUseDeadline(
+		10*time.Millisecond,
+		func() (chan struct{}, error) { return make(chan struct{}), nil },
+		func(conn chan struct{}) error {
+			fmt.Println("released")
+			close(conn)
+			return nil
+		},
+		func(conn chan struct{}) error {
+			<-conn // blocks until the connection is closed
+			close(interrupted)
+			return errors.New("use of closed connection")
+		},
+	)
+	<-interrupted
+	fmt.Println(err)
+	// Output:
+	// released
+	// context deadline exceeded
+}
+
+func ExampleZero() {
+	fmt.Println(brago.Zero[*os.File]() == nil)
+	fmt.Printf("%+v\n", brago.Zero[struct {
+		Name string
+		Size int
+	}]())
+	fmt.Println(brago.Zero[[]byte]() == nil)
+	// Output:
+	// true
+	// {Name: Size:0}
+	// true
+}
+
+func ExampleBracketR2() {
+	for _, errs := range [][2]error{
+		{nil, nil},
+		{errors.New("use failed"), nil},
+		{nil, errors.New("release failed")},
+		{errors.New("use failed"), errors.New("release failed")},
+	} {
+		a, useErr, releaseErr := brago.BracketR2(
+			func() (int, error) { return 21, nil },
+			func(int) error { return errs[1] },
+			func(r int) (int, error) {
+				if errs[0] != nil {
+					return 0, errs[0]
+				}
+				return r * 2, nil
+			},
+		)
+		fmt.Println(a, useErr, releaseErr)
+	}
+	// Output:
+	// 42 <nil> <nil>
+	// 0 use failed <nil>
+	// 42 <nil> release failed
+	// 0 use failed release failed
+}
+
+func ExampleChain() {
+	trace := func(name string) brago.Middleware[string] {
+		return func(use func(string) error) func(string) error {
+			return func(r string) error {
+				fmt.Println("enter", name)
+				err := use(r)
+				fmt.Println("leave", name)
+				return err
+			}
+		}
+	}
+	timing := func(use func(string) error) func(string) error {
+		return func(r string) error {
+			start := time.Now()
+			err := use(r)
+			_ = time.Since(start) // record the duration somewhere
+			return err
+		}
+	}
+
+	err := brago.Bracket(
+		func() (string, error) { return "conn", nil },
+		func(string) error { return nil },
+		brago.Chain(trace("outer"), timing, trace("inner"))(func(r string) error {
+			fmt.Println("use", r)
+			return nil
+		}),
+	)
+	fmt.Println(err)
+	// Output:
+	// enter outer
+	// enter inner
+	// use conn
+	// leave inner
+	// leave outer
+	// <nil>
+}
+
+func ExampleBracketAsync() {
+	release := func(r string) error {
+		fmt.Println("released", r)
+		return nil
+	}
+
+	done := brago.BracketAsync(
+		func() (string, error) { return "conn", nil },
+		release,
+		func(string) error { panic("boom") },
+	)
+	select {
+	case err := <-done:
+		fmt.Println(err)
+	case <-time.After(time.Second):
+		fmt.Println("timed out")
+	}
+	_, ok := <-done
+	fmt.Println(ok)
+	// Output:
+	// released conn
+	// brago: recovered panic: boom
+	// false
+}
+
+type canceler struct{}
+
+func (canceler) Cancel() { fmt.Println("cancelled") }
+
+type stopper struct{}
+
+func (stopper) Stop() { fmt.Println("stopped") }
+
+// Stop is never called, since Close takes precedence.
+func (*countingCloser) Stop() { fmt.Println("stopped") }
+
+func ExampleWithAny() {
+	c := &countingCloser{}
+	for _, r := range []any{c, stopper{}, canceler{}, "plain"} {
+		err := brago.WithAny(
+			func() (any, error) { return r, nil },
+			func(any) error { return nil },
+		)
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+	fmt.Println("closed", c.closes)
+	// Output:
+	// stopped
+	// cancelled
+	// closed 1
+}
+
+func ExampleOnReleaseError() {
+	brago.OnReleaseError = func(err error) { fmt.Println("release failed:", err) }
+	defer func() { brago.OnReleaseError = nil }()
+
+	for _, releaseErr := range []error{nil, errors.New("close: broken pipe")} {
+		err := brago.Bracket(
+			func() (int, error) { return 1, nil },
+			func(int) error { return releaseErr },
+			func(int) error { return errors.New("use failed") },
+		)
+		fmt.Println(err)
+	}
+	// Output:
+	// use failed
+	// release failed: close: broken pipe
+	// use failed
+	// close: broken pipe
+}
+
+func ExampleCombinePolicy() {
+	defer brago.SetDefaultCombinePolicy(brago.DefaultCombinePolicy())
+
+	for _, p := range []brago.CombinePolicy{brago.JoinAll, brago.UseErrorWins, brago.ReleaseErrorWins, brago.FirstNonNil} {
+		brago.SetDefaultCombinePolicy(p)
+		err := brago.Bracket(
+			func() (int, error) { return 1, nil },
+			func(int) error { return errors.New("release failed") },
+			func(int) error { return errors.New("use failed") },
+		)
+		fmt.Printf("%q\n", err)
+	}
+	// Output:
+	// "use failed\nrelease failed"
+	// "use failed"
+	// "release failed"
+	// "use failed"
+}
+
+func ExampleWithHandoff() {
+	releases := 0
+	conn, done, err := brago.WithHandoff(
+		func() (string, error) { return "conn", nil },
+		func(string) error {
+			releases++
+			return nil
+		},
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			done(errors.New("handled " + conn))
+		}()
+	}
+	wg.Wait()
+	fmt.Println(releases, done(nil))
+	// Output: 1 handled conn
+}
+
+func ExampleUseError() {
+	for _, errs := range [][2]error{
+		{errors.New("use failed"), errors.New("release failed")},
+		{errors.New("use failed"), nil},
+		{nil, errors.New("release failed")},
+	} {
+		err := brago.Bracket(
+			func() (int, error) { return 1, nil },
+			func(int) error { return errs[1] },
+			func(int) error { return errs[0] },
+		)
+		fmt.Println(brago.IsReleaseFailure(err), brago.UseError(err))
+	}
+	// Output:
+	// true use failed
+	// false use failed
+	// true <nil>
+}
+
+func ExampleUsing() {
+	acquire := func() (string, error) { return "conn", nil }
+	release := func(r string) error {
+		fmt.Println("released", r)
+		return nil
+	}
+
+	for r, err := range brago.Using(acquire, release) {
+		fmt.Println("used", r, err)
+	}
+
+	for r := range brago.Using(acquire, release) {
+		fmt.Println("broke out with", r)
+		break
+	}
+
+	func() {
+		defer func() { fmt.Println("recovered", recover()) }()
+		for range brago.Using(acquire, release) {
+			panic("boom")
+		}
+	}()
+	// Output:
+	// used conn <nil>
+	// released conn
+	// broke out with conn
+	// released conn
+	// released conn
+	// recovered boom
+}
+
+func ExampleUseSeq() {
+	release := func([]string) error {
+		fmt.Println("released")
+		return nil
+	}
+	use := func(row string) error {
+		if row == "corrupt" {
+			return errors.New("corrupt row")
+		}
+		fmt.Println(row)
+		return nil
+	}
+
+	for _, rows := range [][]string{{"a", "b"}, {"a", "corrupt", "b"}} {
+		err := brago.UseSeq(
+			func() ([]string, error) { return rows, nil },
+			release,
+			func(rows []string) iter.Seq[string] { return slices.Values(rows) },
+			use,
+		)
+		fmt.Println(err)
+	}
+	// Output:
+	// a
+	// b
+	// released
+	// <nil>
+	// a
+	// released
+	// corrupt row
+}
+
+func ExampleBracketView() {
+	var file *os.File
+	err := brago.BracketView(
+		func() (*os.File, error) {
+			f, err := os.Open("LICENSE")
+			file = f
+			return f, err
+		},
+		func(f *os.File) io.Reader { return f },
+		(*os.File).Close,
+		func(r io.Reader) error {
+			b := make([]byte, 3)
+			_, err := io.ReadFull(r, b)
+			fmt.Println(string(b))
+			return err
+		},
+	)
+	fmt.Println(err, errors.Is(file.Close(), os.ErrClosed))
+	// Output:
+	// BSD
+	// <nil> true
+}
+
+func explode(string) error {
+	panic("boom")
+}
+
+func ExampleBracketRecover() {
+	err := brago.BracketRecover(
+		func() (string, error) { return "conn", nil },
+		func(r string) error {
+			fmt.Println("released", r)
+			return nil
+		},
+		explode,
+	)
+
+	var pe *brago.PanicError
+	if errors.As(err, &pe) {
+		fmt.Println(pe.Value, strings.Contains(string(pe.Stack), "explode"))
+	}
+	// Output:
+	// released conn
+	// boom true
+}
+
+func ExampleBracketCommit() {
+	step := func(name string, fail bool) func(string) error {
+		return func(string) error {
+			fmt.Println(name)
+			if fail {
+				return errors.New(name + " failed")
+			}
+			return nil
+		}
+	}
+
+	for _, fail := range [][3]bool{
+		{false, false, false},
+		{true, false, false},
+		{false, true, false},
+		{false, false, true},
+	} {
+		err := brago.BracketCommit(
+			func() (string, error) { return "batch", nil },
+			step("prepare", fail[1]),
+			step("commit", fail[2]),
+			step("rollback", false),
+			step("use", fail[0]),
+		)
+		fmt.Println("=>", err)
+	}
+	// Output:
+	// use
+	// prepare
+	// commit
+	// => <nil>
+	// use
+	// rollback
+	// => use failed
+	// use
+	// prepare
+	// rollback
+	// => prepare failed
+	// use
+	// prepare
+	// commit
+	// => commit failed
+}
+
+func ExampleBracketVariadic() {
+	factory := func(name string, err error) func() (string, error) {
+		return func() (string, error) {
+			if err != nil {
+				return "", err
+			}
+			fmt.Println("acquired", name)
+			return name, nil
+		}
+	}
+
+	err := brago.BracketVariadic(
+		[]func() (string, error){
+			factory("primary", nil),
+			factory("replica", nil),
+			factory("cache", errors.New("cache unavailable")),
+		},
+		func(r string) error {
+			fmt.Println("released", r)
+			return nil
+		},
+		func([]string) error {
+			fmt.Println("used")
+			return nil
+		},
+	)
+	fmt.Println(err)
+	// Output:
+	// acquired primary
+	// acquired replica
+	// released replica
+	// released primary
+	// cache unavailable
+}
+
+func ExampleWithWorker() {
+	ticks := make(chan int)
+	err := brago.WithWorker(
+		func(ctx context.Context) error {
+			for i := 1; ; i++ {
+				select {
+				case ticks <- i:
+				case <-ctx.Done():
+					fmt.Println("worker stopped")
+					return errors.New("worker failed")
+				}
+			}
+		},
+		func(ctx context.Context) error {
+			fmt.Println(<-ticks, <-ticks)
+			return nil
+		},
+	)
+	fmt.Println(err)
+	// Output:
+	// 1 2
+	// worker stopped
+	// worker failed
+}
+
+func ExampleWithPermit() {
+	sem := brago.NewSemaphore(2)
+
+	var (
+		mu            sync.Mutex
+		running, peak int
+		wg            sync.WaitGroup
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			brago.WithPermit(sem, func() error {
+				mu.Lock()
+				running++
+				peak = max(peak, running)
+				mu.Unlock()
+
+				time.Sleep(time.Millisecond)
+
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	fmt.Println(peak <= 2, len(sem))
+	// Output: true 0
+}
+
+func ExampleWithPool() {
+	var (
+		ready atomic.Int32
+		sum   atomic.Int64
+	)
+	err := brago.WithPool(
+		4,
+		func(ctx context.Context, id int) error {
+			ready.Add(1)
+			return nil
+		},
+		func(submit func(func())) error {
+			for i := 1; i <= 100; i++ {
+				submit(func() { sum.Add(int64(i)) })
+			}
+			return nil
+		},
+	)
+	fmt.Println(ready.Load(), sum.Load(), err)
+
+	// Every task runs for the lifetime of the pool and stops once the pool is closed, while the
+	// workers take the submitted functions meanwhile.
+	sum.Store(0)
+	var stopped atomic.Int32
+	err = brago.WithPool(
+		2,
+		func(ctx context.Context, id int) error {
+			<-ctx.Done()
+			stopped.Add(1)
+			return ctx.Err()
+		},
+		func(submit func(func())) error {
+			for i := 1; i <= 10; i++ {
+				submit(func() { sum.Add(int64(i)) })
+			}
+			return nil
+		},
+	)
+	fmt.Println(sum.Load(), stopped.Load(), err)
+
+	// A pool of no workers still has one.
+	err = brago.WithPool(
+		0,
+		func(context.Context, int) error { return nil },
+		func(submit func(func())) error {
+			submit(func() { fmt.Println("ran") })
+			return nil
+		},
+	)
+	fmt.Println(err)
+	// Output:
+	// 4 5050 <nil>
+	// 55 2 <nil>
+	// ran
+	// <nil>
+}
+
+func ExampleAsyncRelease() {
+	released := make(chan string)
+	err := brago.AsyncRelease(
+		func() (string, error) { return "conn", nil },
+		func(r string) error {
+			released <- r
+			return nil
+		},
+		func(r string) error {
+			fmt.Println("used", r)
+			return nil
+		},
+	)
+	fmt.Println("returned", err)
+	fmt.Println("released", <-released)
+	// Output:
+	// used conn
+	// returned <nil>
+	// released conn
+}
+
+func ExampleBracketUseRetry() {
+	conns := 0
+	err := brago.BracketUseRetry(
+		3,
+		func() (int, error) {
+			conns++
+			return conns, nil
+		},
+		func(r int) error {
+			fmt.Println("released conn", r)
+			return nil
+		},
+		func(r int) error {
+			if r == 1 {
+				return errors.New("connection reset")
+			}
+			fmt.Println("queried on conn", r)
+			return nil
+		},
+	)
+	fmt.Println(err)
+	// Output:
+	// released conn 1
+	// queried on conn 2
+	// released conn 2
+	// <nil>
+}
+
+func ExampleBracketFinalized() {
+	brago.OnReleaseError = func(err error) { fmt.Println("reported:", err) }
+	defer func() { brago.OnReleaseError = nil }()
+
+	// Had use panicked, the skipped release would be reported once the garbage collector runs.
+	err := brago.BracketFinalized(
+		func() (string, error) { return "conn", nil },
+		func(string) error {
+			fmt.Println("released")
+			return nil
+		},
+		func(string) error { return nil },
+	)
+	fmt.Println(err)
+	// Output:
+	// released
+	// <nil>
+}
+
+func ExampleBracketReport() {
+	rep := brago.BracketReport(
+		func() (int, error) {
+			time.Sleep(time.Millisecond)
+			return 1, nil
+		},
+		func(int) error { return errors.New("flush failed") },
+		func(int) error {
+			time.Sleep(time.Millisecond)
+			return nil
+		},
+	)
+	fmt.Println(rep.AcquireDuration > 0, rep.UseDuration > 0, rep.ReleaseDuration > 0)
+	fmt.Println(rep.UseErr, rep.ReleaseErr)
+	fmt.Println(brago.IsReleaseFailure(rep.Err()))
+	// Output:
+	// true true true
+	// <nil> flush failed
+	// true
+}
+
+func ExampleBracketPaginate() {
+	pages := []string{"a", "b", "c"}
+	err := brago.BracketPaginate(
+		func() ([]string, error) { return pages, nil },
+		func([]string) error {
+			fmt.Println("released")
+			return nil
+		},
+		func(r []string, cursor int) ([]byte, int, bool, error) {
+			return []byte(r[cursor]), cursor + 1, cursor+1 < len(r), nil
+		},
+		0,
+		func(page []byte) error {
+			fmt.Println(string(page))
+			return nil
+		},
+	)
+	fmt.Println(err)
+	// Output:
+	// a
+	// b
+	// c
+	// released
+	// <nil>
+}
+
+func ExampleBracketProgress() {
+	err := brago.BracketProgress(
+		func() ([]byte, error) { return make([]byte, 300), nil },
+		func([]byte) error {
+			fmt.Println("released")
+			return nil
+		},
+		func(done, total int64) { fmt.Printf("%d/%d\n", done, total) },
+		func(r []byte, report func(done, total int64)) error {
+			for done := 100; done <= len(r); done += 100 {
+				report(int64(done), int64(len(r)))
+			}
+			return nil
+		},
+	)
+	fmt.Println(err)
+	// Output:
+	// 100/300
+	// 200/300
+	// 300/300
+	// released
+	// <nil>
+}
@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package brago
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// ErrNotReleased is reported to [OnReleaseError] by [BracketFinalized] when the resource was never
+// released.
+var ErrNotReleased = errors.New("brago: resource was not released")
+
+// BracketFinalized is like [Bracket], but backed by a finalizer as a safety net. If the bracket
+// ends without the resource being released, e.g. because use panicked, [ErrNotReleased] is
+// reported to [OnReleaseError] once the garbage collector notices it. The finalizer is cleared as
+// soon as the resource is released.
+func BracketFinalized[R any](acquire func() (R, error), release func(R) error, use func(R) error) error {
+	g := newFinalizerGuard()
+
+	return Bracket(
+		func() (R, error) {
+			r, err := acquire()
+			if err != nil {
+				g.clear()
+			}
+			return r, err
+		},
+		func(r R) error {
+			g.clear()
+			return release(r)
+		},
+		use,
+	)
+}
+
+// onFinalizerGuard is called, if set, with every guard created by [BracketFinalized]. It lets the
+// tests run the finalizer of a guard with finalize instead of waiting for the garbage collector.
+var onFinalizerGuard func(*finalizerGuard)
+
+// finalizerGuard carries the finalizer of [BracketFinalized]. It has a pointer field, since the
+// finalizers of the tiny objects without pointers may never run, and so do the ones of zero-sized
+// objects.
+type finalizerGuard struct {
+	mu      sync.Mutex
+	cleared bool
+	report  func(error) error
+}
+
+func newFinalizerGuard() *finalizerGuard {
+	g := &finalizerGuard{report: released}
+	runtime.SetFinalizer(g, (*finalizerGuard).finalize)
+	if onFinalizerGuard != nil {
+		onFinalizerGuard(g)
+	}
+	return g
+}
+
+// finalize reports [ErrNotReleased] unless the guard was cleared. It runs as the finalizer.
+func (g *finalizerGuard) finalize() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.cleared {
+		g.cleared = true
+		g.report(ErrNotReleased)
+	}
+}
+
+func (g *finalizerGuard) clear() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.cleared = true
+	runtime.SetFinalizer(g, nil)
+}
@@ -0,0 +1,43 @@
+package brago
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBracketFinalized(t *testing.T) {
+	var guards []*finalizerGuard
+	onFinalizerGuard = func(g *finalizerGuard) { guards = append(guards, g) }
+	var reported []error
+	OnReleaseError = func(err error) { reported = append(reported, err) }
+	t.Cleanup(func() { onFinalizerGuard, OnReleaseError = nil, nil })
+
+	// Released: the finalizer reports nothing.
+	BracketFinalized(
+		func() (string, error) { return "conn", nil },
+		func(string) error { return nil },
+		func(string) error { return nil },
+	)
+
+	// Not released: use panics, which skips the release.
+	func() {
+		defer func() { recover() }()
+		BracketFinalized(
+			func() (string, error) { return "conn", nil },
+			func(string) error { return nil },
+			func(string) error { panic("boom") },
+		)
+	}()
+
+	if len(guards) != 2 {
+		t.Fatalf("got %d guards, want 2", len(guards))
+	}
+	for _, g := range guards {
+		g.finalize()
+		g.finalize()
+	}
+
+	if len(reported) != 1 || !errors.Is(reported[0], ErrNotReleased) {
+		t.Fatalf("reported %v, want a single %v", reported, ErrNotReleased)
+	}
+}
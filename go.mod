@@ -1,3 +1,3 @@
 module github.com/thelissimus/brago
 
-go 1.22.1
+go 1.23
@@ -0,0 +1,43 @@
+package gob_test
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	bgob "github.com/thelissimus/brago/gob"
+)
+
+type point struct {
+	X, Y int
+}
+
+func ExampleWithEncoderFile() {
+	dir, err := os.MkdirTemp("", "brago-example")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "points.gob")
+
+	err = bgob.WithEncoderFile(name, func(enc *gob.Encoder) error {
+		return enc.Encode([]point{{1, 2}, {3, 4}})
+	})
+	fmt.Println(err)
+
+	err = bgob.WithDecoderFile(name, func(dec *gob.Decoder) error {
+		var points []point
+		if err := dec.Decode(&points); err != nil {
+			return err
+		}
+		fmt.Println(points)
+		return nil
+	})
+	fmt.Println(err)
+	// Output:
+	// <nil>
+	// [{1 2} {3 4}]
+	// <nil>
+}
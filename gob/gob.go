@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+/* Wrappers of brago for stdlib encoding/gob package. */
+package gob
+
+import (
+	"encoding/gob"
+	"errors"
+	"os"
+
+	"github.com/thelissimus/brago"
+	bos "github.com/thelissimus/brago/os"
+)
+
+// WithEncoderFile creates the file and passes an encoder writing to it to use. Afterwards the file
+// is synced and closed.
+func WithEncoderFile(name string, use func(*gob.Encoder) error) error {
+	return brago.Bracket(
+		func() (*os.File, error) { return os.Create(name) },
+		func(f *os.File) error { return errors.Join(f.Sync(), f.Close()) },
+		func(f *os.File) error { return use(gob.NewEncoder(f)) },
+	)
+}
+
+// WithDecoderFile opens the file and passes a decoder reading from it to use. Afterwards the file
+// is closed.
+func WithDecoderFile(name string, use func(*gob.Decoder) error) error {
+	return bos.WithOpen(name, func(f *os.File) error { return use(gob.NewDecoder(f)) })
+}
@@ -0,0 +1,188 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	bhttp "github.com/thelissimus/brago/http"
+)
+
+func ExampleWithGetContext() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "partial")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := bhttp.WithGetContext(ctx, srv.URL, func(r *http.Response) error {
+		_, err := io.ReadAll(r.Body)
+		return err
+	})
+	fmt.Println(errors.Is(err, context.DeadlineExceeded))
+	// Output: true
+}
+
+func ExampleWithGetDrained() {
+	var conns atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, strings.Repeat("unread ", 1000))
+	}))
+	srv.Config.ConnState = func(_ net.Conn, s http.ConnState) {
+		if s == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	for i := 0; i < 3; i++ {
+		err := bhttp.WithGetDrained(srv.URL, func(r *http.Response) error { return nil })
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+	fmt.Println(conns.Load())
+	// Output: 1
+}
+
+func ExampleWithGetBody() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "0123456789")
+	}))
+	defer srv.Close()
+
+	err := bhttp.WithGetBody(srv.URL, 10, func(b []byte) error {
+		fmt.Println(string(b))
+		return nil
+	})
+	fmt.Println(err)
+
+	err = bhttp.WithGetBody(srv.URL, 9, func(b []byte) error {
+		fmt.Println(string(b))
+		return nil
+	})
+	fmt.Println(err)
+	// Output:
+	// 0123456789
+	// <nil>
+	// brago/http: body too large
+}
+
+type trackedBody struct {
+	io.Reader
+	closes int
+}
+
+func (b *trackedBody) Close() error {
+	b.closes++
+	return nil
+}
+
+func ExampleWithRequestBody() {
+	body := &trackedBody{Reader: strings.NewReader(`{"name":"gopher"}`)}
+	err := bhttp.WithRequestBody(http.MethodPost, "http://example.com", body, func(req *http.Request) error {
+		return errors.New("aborted before sending")
+	})
+	fmt.Println(err, body.closes)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	body = &trackedBody{Reader: strings.NewReader(`{"name":"gopher"}`)}
+	err = bhttp.WithRequestBody(http.MethodPost, srv.URL, body, func(req *http.Request) error {
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	})
+	fmt.Println(err, body.closes)
+
+	err = bhttp.WithRequestBody(http.MethodGet, srv.URL, nil, func(req *http.Request) error {
+		fmt.Println(req.Body == nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	})
+	fmt.Println(err)
+	// Output:
+	// aborted before sending 1
+	// <nil> 1
+	// true
+	// <nil>
+}
+
+func ExampleWithRoundTrip() {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusTeapot)
+		io.WriteString(w, "short and stout")
+	})
+
+	var body io.Reader
+	req := httptest.NewRequest(http.MethodGet, "/teapot", nil)
+	err := bhttp.WithRoundTrip(handler, req, func(r *http.Response) error {
+		body = r.Body
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		fmt.Println(r.StatusCode, r.Header.Get("Content-Type"), string(b))
+		return nil
+	})
+	fmt.Println(err)
+
+	_, err = body.Read(make([]byte, 1))
+	fmt.Println(err)
+	// Output:
+	// 418 text/plain short and stout
+	// <nil>
+	// http: invalid Read on closed Body
+}
+
+func ExampleWithStream() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "first chunk")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var body io.Reader
+	err := bhttp.WithStream(ctx, srv.URL, func(r io.Reader) error {
+		body = r
+		buf := make([]byte, len("first chunk"))
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return err
+		}
+		fmt.Println(string(buf))
+
+		// The next chunk never comes, until the stream is cancelled.
+		time.AfterFunc(10*time.Millisecond, cancel)
+		_, err := r.Read(buf)
+		return err
+	})
+	fmt.Println(err)
+
+	_, err = body.Read(make([]byte, 1))
+	fmt.Println(err != nil)
+	// Output:
+	// first chunk
+	// context canceled
+	// true
+}
@@ -0,0 +1,171 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+/* Wrappers of brago for stdlib net/http package. */
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+
+	"github.com/thelissimus/brago"
+	bio "github.com/thelissimus/brago/io"
+)
+
+// ErrBodyTooLarge is returned by [WithGetBody] if the body exceeds the limit.
+var ErrBodyTooLarge = errors.New("brago/http: body too large")
+
+// WithResponse is used to manually acquire the response and automatically close its body.
+func WithResponse(acquire func() (*http.Response, error), use func(*http.Response) error) error {
+	return brago.Bracket(acquire, func(r *http.Response) error { return r.Body.Close() }, use)
+}
+
+// WithGetContext is a wrapper for [pkg/net/http.Client.Do] of [pkg/net/http.DefaultClient] with a
+// GET request bound to ctx.
+func WithGetContext(ctx context.Context, url string, use func(*http.Response) error) error {
+	return WithResponse(
+		func() (*http.Response, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return nil, err
+			}
+			return http.DefaultClient.Do(req)
+		},
+		use,
+	)
+}
+
+// WithGetDrained is like a wrapper for [pkg/net/http.Get], but the rest of the body is drained
+// before it is closed, so the connection can be reused by the next request.
+func WithGetDrained(url string, use func(*http.Response) error) error {
+	return brago.Bracket(
+		func() (*http.Response, error) { return http.Get(url) },
+		func(r *http.Response) error { return errors.Join(bio.Drain(r.Body), r.Body.Close()) },
+		use,
+	)
+}
+
+// WithStream is like [WithGetContext], but only the body of the response is passed to use, to
+// stream it. If ctx is done while use runs, the body is closed to interrupt use and the error of ctx
+// is returned. Otherwise the rest of the body is drained before it is closed.
+func WithStream(ctx context.Context, url string, use func(io.Reader) error) error {
+	return WithGetContext(ctx, url, func(r *http.Response) error {
+		stop := context.AfterFunc(ctx, func() { r.Body.Close() })
+		err := use(r.Body)
+		if !stop() {
+			return ctx.Err()
+		}
+		return errors.Join(err, bio.Drain(r.Body))
+	})
+}
+
+// WithGetBody is a wrapper for [pkg/net/http.Get] which reads the whole body, closes it and passes
+// the read bytes to use. If the body is longer than maxBytes, [ErrBodyTooLarge] is returned
+// without reading the rest of it.
+func WithGetBody(url string, maxBytes int64, use func([]byte) error) error {
+	var b []byte
+	err := WithResponse(
+		func() (*http.Response, error) { return http.Get(url) },
+		func(r *http.Response) (err error) {
+			b, err = io.ReadAll(io.LimitReader(r.Body, maxBytes+1))
+			if err != nil {
+				return err
+			}
+			if int64(len(b)) > maxBytes {
+				return ErrBodyTooLarge
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return use(b)
+}
+
+// WithRequestBody is a wrapper for [pkg/net/http.NewRequest] with the given body. The use is
+// expected to send the request, which closes the body. If it does not, e.g. because it fails
+// before sending, the body is closed afterwards. A nil body makes a request without a body.
+func WithRequestBody(method, url string, body io.ReadCloser, use func(*http.Request) error) error {
+	// The body MUST stay a nil interface if there is none, so the request has no body.
+	var (
+		b       *onceCloser
+		reqBody io.Reader
+	)
+	if body != nil {
+		b = &onceCloser{ReadCloser: body}
+		reqBody = b
+	}
+
+	return brago.Bracket(
+		func() (*http.Request, error) {
+			req, err := http.NewRequest(method, url, reqBody)
+			if err != nil {
+				// MUST NOT leak the body if the request cannot be built!
+				return nil, errors.Join(err, b.Close())
+			}
+			return req, nil
+		},
+		func(*http.Request) error { return b.Close() },
+		use,
+	)
+}
+
+// WithRoundTrip serves req with handler in memory, using [pkg/net/http/httptest.ResponseRecorder],
+// and passes the recorded response to use. Afterwards the body of the response is closed, so like
+// the body of a real response, it cannot be read any more.
+func WithRoundTrip(handler http.Handler, req *http.Request, use func(*http.Response) error) error {
+	return WithResponse(
+		func() (*http.Response, error) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			res.Request = req
+			res.Body = &recordedBody{Reader: res.Body}
+			return res, nil
+		},
+		use,
+	)
+}
+
+// recordedBody is the body of a recorded response, which fails to be read after it is closed.
+type recordedBody struct {
+	io.Reader
+	closed atomic.Bool
+}
+
+func (b *recordedBody) Read(p []byte) (int, error) {
+	if b.closed.Load() {
+		return 0, http.ErrBodyReadAfterClose
+	}
+	return b.Reader.Read(p)
+}
+
+func (b *recordedBody) Close() error {
+	b.closed.Store(true)
+	return nil
+}
+
+// onceCloser closes the underlying body only once, so the transport and the bracket can both
+// close it.
+type onceCloser struct {
+	io.ReadCloser
+	once sync.Once
+	err  error
+}
+
+// Close closes the underlying body, unless it was closed already. It does nothing on a nil
+// onceCloser, which stands for no body.
+func (c *onceCloser) Close() error {
+	if c == nil {
+		return nil
+	}
+	c.once.Do(func() { c.err = c.ReadCloser.Close() })
+	return c.err
+}
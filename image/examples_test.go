@@ -0,0 +1,38 @@
+package image_test
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+
+	bimage "github.com/thelissimus/brago/image"
+)
+
+type closingReader struct {
+	io.Reader
+}
+
+func (closingReader) Close() error {
+	fmt.Println("closed")
+	return nil
+}
+
+func ExampleWithDecode() {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 3))); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	err := bimage.WithDecode(closingReader{&buf}, func(img image.Image, format string) error {
+		fmt.Println(format, img.Bounds().Dx(), img.Bounds().Dy())
+		return nil
+	})
+	fmt.Println(err)
+	// Output:
+	// png 4 3
+	// closed
+	// <nil>
+}
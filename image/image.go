@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+/* Wrappers of brago for stdlib image package. */
+package image
+
+import (
+	"image"
+	"io"
+
+	"github.com/thelissimus/brago"
+)
+
+// WithDecode is a wrapper for [pkg/image.Decode]. Afterwards r is closed if it implements
+// io.Closer. Only the formats registered by the program can be decoded, e.g. by importing
+// image/png.
+func WithDecode(r io.Reader, use func(img image.Image, format string) error) error {
+	return brago.Bracket(
+		func() (io.Reader, error) { return r, nil },
+		func(r io.Reader) error {
+			if c, ok := r.(io.Closer); ok {
+				return c.Close()
+			}
+			return nil
+		},
+		func(r io.Reader) error {
+			img, format, err := image.Decode(r)
+			if err != nil {
+				return err
+			}
+			return use(img, format)
+		},
+	)
+}
@@ -0,0 +1,164 @@
+package io_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/thelissimus/brago"
+	bio "github.com/thelissimus/brago/io"
+)
+
+type limitedWriter struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.buf.Len()+len(p) > w.limit {
+		return 0, errors.New("disk full")
+	}
+	return w.buf.Write(p)
+}
+
+func (w *limitedWriter) Close() error {
+	fmt.Println("closed with", w.buf.Len(), "bytes")
+	return nil
+}
+
+func ExampleWithWriteStack() {
+	dir, err := os.MkdirTemp("", "brago-example")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "data.gz")
+
+	err = bio.WithWriteStack(path, bio.WriteStackOpts{Gzip: true, BufferSize: 16}, func(w io.Writer) error {
+		_, err := io.WriteString(w, "hello, layered world")
+		return err
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	b, err := io.ReadAll(zr)
+	fmt.Println(string(b), err)
+	// Output: hello, layered world <nil>
+}
+
+func ExampleWithWriteTo() {
+	dst := func(limit int) brago.Resource[io.WriteCloser] {
+		return brago.NewResource(
+			func() (io.WriteCloser, error) { return &limitedWriter{limit: limit}, nil },
+			io.WriteCloser.Close,
+		)
+	}
+
+	n, err := bio.WithWriteTo(strings.NewReader("hello"), dst(10))
+	fmt.Println(n, err)
+
+	n, err = bio.WithWriteTo(strings.NewReader("hello"), dst(3))
+	fmt.Println(n, err)
+	// Output:
+	// closed with 5 bytes
+	// 5 <nil>
+	// closed with 0 bytes
+	// 0 disk full
+}
+
+func ExampleWithMultiWriter() {
+	file, network := &limitedWriter{limit: 100}, &limitedWriter{limit: 100}
+	err := bio.WithMultiWriter([]io.WriteCloser{file, network}, func(w io.Writer) error {
+		_, err := io.WriteString(w, "tee")
+		return err
+	})
+	fmt.Println(err, file.buf.String(), network.buf.String())
+	// Output:
+	// closed with 3 bytes
+	// closed with 3 bytes
+	// <nil> tee tee
+}
+
+func ExampleWithHashCopy() {
+	dst := &limitedWriter{limit: 16}
+	err := bio.WithHashCopy(dst, strings.NewReader("hello"), sha256.New(), func(sum []byte) error {
+		fmt.Printf("%x\n", sum)
+		return nil
+	})
+	fmt.Println(err)
+
+	err = bio.WithHashCopy(&limitedWriter{limit: 4}, strings.NewReader("hello"), sha256.New(), func(sum []byte) error {
+		fmt.Println("not called")
+		return nil
+	})
+	fmt.Println(err)
+	// Output:
+	// closed with 5 bytes
+	// 2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824
+	// <nil>
+	// closed with 0 bytes
+	// disk full
+}
+
+func ExampleWithDecompress() {
+	var gzipped, zlibbed bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	io.WriteString(gw, "gzip data")
+	gw.Close()
+	zw := zlib.NewWriter(&zlibbed)
+	io.WriteString(zw, "zlib data")
+	zw.Close()
+
+	inputs := []io.Reader{
+		&gzipped,
+		&zlibbed,
+		strings.NewReader("plain data"),
+		// Plain data which starts like a zlib or bzip2 header.
+		strings.NewReader("x^2 + y^2"),
+		strings.NewReader("x^2"),
+		strings.NewReader("BZh9 is the largest block size"),
+		strings.NewReader("BZh9"),
+	}
+	for _, r := range inputs {
+		err := bio.WithDecompress(r, func(r io.Reader) error {
+			b, err := io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		})
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+	// Output:
+	// gzip data
+	// zlib data
+	// plain data
+	// x^2 + y^2
+	// x^2
+	// BZh9 is the largest block size
+	// BZh9
+}
@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+/* Wrappers of brago for stdlib io package and its layered readers and writers. */
+package io
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/thelissimus/brago"
+	bos "github.com/thelissimus/brago/os"
+)
+
+// Drain reads r until EOF and discards the data.
+func Drain(r io.Reader) error {
+	_, err := io.Copy(io.Discard, r)
+	return err
+}
+
+// WithWriteTo acquires dst and writes wt to it with [pkg/io.WriterTo.WriteTo]. The number of
+// bytes written is returned once dst is closed.
+func WithWriteTo(wt io.WriterTo, dst brago.Resource[io.WriteCloser]) (int64, error) {
+	var n int64
+	err := dst(func(w io.WriteCloser) (err error) {
+		n, err = wt.WriteTo(w)
+		return err
+	})
+	return n, err
+}
+
+// WithMultiWriter passes a writer duplicating its writes to all of ws, like [pkg/io.MultiWriter],
+// to use. Afterwards every writer is closed in reverse order, even if closing another one fails.
+func WithMultiWriter(ws []io.WriteCloser, use func(io.Writer) error) error {
+	return brago.Bracket(
+		func() ([]io.WriteCloser, error) { return ws, nil },
+		func(ws []io.WriteCloser) error {
+			errs := make([]error, 0, len(ws))
+			for i := len(ws) - 1; i >= 0; i-- {
+				errs = append(errs, ws[i].Close())
+			}
+			return errors.Join(errs...)
+		},
+		func(ws []io.WriteCloser) error {
+			writers := make([]io.Writer, len(ws))
+			for i, w := range ws {
+				writers[i] = w
+			}
+			return use(io.MultiWriter(writers...))
+		},
+	)
+}
+
+// WithHashCopy copies src to dst while hashing the copied data with h. Afterwards dst is closed, so
+// the data is flushed, and the checksum is passed to use. If the copy or the close fails, use is
+// not called, as the checksum would not match the data in dst.
+func WithHashCopy(dst io.WriteCloser, src io.Reader, h hash.Hash, use func(sum []byte) error) error {
+	err := brago.WithResource(
+		func() (io.WriteCloser, error) { return dst, nil },
+		func(w io.WriteCloser) error {
+			_, err := io.Copy(io.MultiWriter(w, h), src)
+			return err
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return use(h.Sum(nil))
+}
+
+// WithDecompress detects the compression of r by its magic bytes and passes a reader
+// decompressing it to use. The gzip, zlib and bzip2 formats are detected. Since plain data may
+// start with the same bytes, the beginning of r is test decompressed too. If r is not compressed
+// with any of the formats, the data is passed through as is. Afterwards the decompressor is closed.
+func WithDecompress(r io.Reader, use func(io.Reader) error) error {
+	return brago.Bracket(
+		func() (io.Reader, error) {
+			br := bufio.NewReader(r)
+			prefix, err := br.Peek(probeSize)
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+
+			var open func(io.Reader) (io.Reader, error)
+			switch {
+			case bytes.HasPrefix(prefix, []byte{0x1f, 0x8b, 0x08}):
+				open = func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }
+			case len(prefix) >= 2 && isZlibHeader(prefix[0], prefix[1]):
+				open = func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) }
+			case len(prefix) >= 4 && bytes.HasPrefix(prefix, []byte("BZh")) && '1' <= prefix[3] && prefix[3] <= '9':
+				open = func(r io.Reader) (io.Reader, error) { return bzip2.NewReader(r), nil }
+			}
+
+			if open == nil || !decompresses(prefix, open) {
+				return br, nil
+			}
+			return open(br)
+		},
+		func(r io.Reader) error {
+			if c, ok := r.(io.Closer); ok {
+				return c.Close()
+			}
+			return nil
+		},
+		use,
+	)
+}
+
+// probeSize is the length of the beginning of the data which [WithDecompress] test decompresses.
+const probeSize = 512
+
+// isZlibHeader reports whether cmf and flg make a zlib header of deflate without a preset
+// dictionary, which is the only kind [pkg/compress/zlib.NewReader] accepts.
+func isZlibHeader(cmf, flg byte) bool {
+	return cmf&0x0f == 8 && cmf>>4 <= 7 && (uint16(cmf)<<8|uint16(flg))%31 == 0 && flg&0x20 == 0
+}
+
+// decompresses reports whether prefix decompresses without an error. A prefix of probeSize bytes
+// may end in the middle of the stream, so ending early is fine then. A shorter prefix is the whole
+// data, so a stream ending early means it is not compressed.
+func decompresses(prefix []byte, open func(io.Reader) (io.Reader, error)) bool {
+	zr, err := open(bytes.NewReader(prefix))
+	if err == nil {
+		_, err = io.Copy(io.Discard, zr)
+	}
+	if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+		return len(prefix) == probeSize
+	}
+	return err == nil
+}
+
+// WriteStackOpts selects the layers of [WithWriteStack].
+type WriteStackOpts struct {
+	// Gzip compresses the written data.
+	Gzip bool
+	// BufferSize is the size of the buffer on top of the stack. Zero means no buffering.
+	BufferSize int
+}
+
+// WithWriteStack creates the file, stacks the layers selected by opts on top of it and passes the
+// topmost one to use. Afterwards the layers are flushed and closed from the top down.
+func WithWriteStack(path string, opts WriteStackOpts, use func(w io.Writer) error) error {
+	return bos.WithCreate(path, func(f *os.File) error {
+		return withGzipWriter(f, opts.Gzip, func(w io.Writer) error {
+			return withBufferedWriter(w, opts.BufferSize, use)
+		})
+	})
+}
+
+func withGzipWriter(w io.Writer, enabled bool, use func(io.Writer) error) error {
+	if !enabled {
+		return use(w)
+	}
+
+	return brago.WithResource(
+		func() (*gzip.Writer, error) { return gzip.NewWriter(w), nil },
+		func(zw *gzip.Writer) error { return use(zw) },
+	)
+}
+
+func withBufferedWriter(w io.Writer, size int, use func(io.Writer) error) error {
+	if size <= 0 {
+		return use(w)
+	}
+
+	return brago.Bracket(
+		func() (*bufio.Writer, error) { return bufio.NewWriterSize(w, size), nil },
+		(*bufio.Writer).Flush,
+		func(bw *bufio.Writer) error { return use(bw) },
+	)
+}
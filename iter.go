@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package brago
+
+import "iter"
+
+// Using is used to acquire the resource in a range-over-func loop, which yields the resource or the
+// error of acquire exactly once:
+//
+//	for f, err := range Using(acquire, release) {
+//		// use
+//	}
+//
+// The resource is released when the loop exits by any means, including break, return and panic.
+// Since the error of the release cannot be returned from the loop, it is only reported to
+// [OnReleaseError].
+func Using[R any](acquire func() (R, error), release func(R) error) iter.Seq2[R, error] {
+	return func(yield func(R, error) bool) {
+		r, err := acquire()
+		if err != nil {
+			yield(r, err)
+			return
+		}
+		defer func() { released(release(r)) }()
+
+		yield(r, nil)
+	}
+}
+
+// UseSeq is like [Bracket], but use is called for every element of the sequence produced from the
+// resource, e.g. the rows of a query. The iteration stops at the first error of use. The resource
+// is released once the iteration is over.
+func UseSeq[R, T any](acquire func() (R, error), release func(R) error, produce func(R) iter.Seq[T], use func(T) error) error {
+	return Bracket(acquire, release, func(r R) error {
+		for v := range produce(r) {
+			if err := use(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
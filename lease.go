@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package brago
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Lease caches the resource between uses. The resource is acquired by the first use and is reused
+// by the following ones. It is released once it has not been used for the ttl. It is safe for
+// concurrent use.
+type Lease[R any] struct {
+	acquire func() (R, error)
+	release func(R) error
+	ttl     time.Duration
+
+	mu    sync.Mutex
+	r     R
+	held  bool
+	users int
+	timer *time.Timer
+	gen   uint64
+	err   error
+}
+
+// NewLease is used to create the [Lease] of the resource.
+func NewLease[R any](acquire func() (R, error), release func(R) error, ttl time.Duration) *Lease[R] {
+	return &Lease[R]{acquire: acquire, release: release, ttl: ttl}
+}
+
+// Use passes the cached resource to use, acquiring it first if there is none.
+func (l *Lease[R]) Use(use func(R) error) error {
+	r, err := l.get()
+	if err != nil {
+		return err
+	}
+	defer l.put()
+
+	return use(r)
+}
+
+// Close releases the cached resource immediately. It returns the error of the release, joined with
+// the errors of the releases which were caused by the expiry of the ttl. It must not be called
+// while the resource is in use.
+func (l *Lease[R]) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.gen++
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+
+	err := l.err
+	l.err = nil
+	if l.held {
+		err = errors.Join(err, released(l.release(l.r)))
+		l.reset()
+	}
+	return err
+}
+
+func (l *Lease[R]) get() (R, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.held {
+		r, err := l.acquire()
+		if err != nil {
+			return r, err
+		}
+		l.r, l.held = r, true
+	}
+
+	l.users++
+	return l.r, nil
+}
+
+func (l *Lease[R]) put() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.users--
+	if l.users > 0 {
+		return
+	}
+
+	l.gen++
+	gen := l.gen
+	if l.timer != nil {
+		l.timer.Stop()
+	}
+	l.timer = time.AfterFunc(l.ttl, func() { l.expire(gen) })
+}
+
+func (l *Lease[R]) expire(gen uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// The resource was used or closed since the timer was started.
+	if gen != l.gen || l.users > 0 || !l.held {
+		return
+	}
+
+	l.err = errors.Join(l.err, released(l.release(l.r)))
+	l.reset()
+}
+
+func (l *Lease[R]) reset() {
+	l.r, l.held, l.timer = Zero[R](), false, nil
+}
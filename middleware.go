@@ -0,0 +1,17 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package brago
+
+// Middleware wraps the use function of a bracket, e.g. to time, log or retry it.
+type Middleware[R any] func(use func(R) error) func(R) error
+
+// Chain composes the middlewares into one. The first middleware is the outermost, so it is the
+// first to run before use and the last to run after it.
+func Chain[R any](mws ...Middleware[R]) Middleware[R] {
+	return func(use func(R) error) func(R) error {
+		for i := len(mws) - 1; i >= 0; i-- {
+			use = mws[i](use)
+		}
+		return use
+	}
+}
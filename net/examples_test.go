@@ -0,0 +1,142 @@
+package net_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/thelissimus/brago"
+	bnet "github.com/thelissimus/brago/net"
+)
+
+func ExampleWithConnHalfClose() {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer l.Close()
+
+	received := make(chan string)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			close(received)
+			return
+		}
+		defer conn.Close()
+		b, _ := io.ReadAll(conn)
+		received <- string(b)
+	}()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	err = bnet.WithConnHalfClose(conn, func(conn net.Conn) error {
+		_, err := io.WriteString(conn, "request")
+		return err
+	})
+	fmt.Println(<-received, err)
+	// Output: request <nil>
+}
+
+func ExampleWithListenPacket() {
+	err := bnet.WithListenPacket("udp", "127.0.0.1:0", func(pc net.PacketConn) error {
+		conn, err := net.Dial("udp", pc.LocalAddr().String())
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if _, err = io.WriteString(conn, "datagram"); err != nil {
+			return err
+		}
+
+		b := make([]byte, 64)
+		n, _, err := pc.ReadFrom(b)
+		fmt.Println(string(b[:n]))
+		return err
+	})
+	fmt.Println(err)
+	// Output:
+	// datagram
+	// <nil>
+}
+
+func ExampleWithKeepAlive() {
+	var pings atomic.Int32
+	err := bnet.WithKeepAlive(
+		5*time.Millisecond,
+		func(string) error {
+			pings.Add(1)
+			return nil
+		},
+		func() (string, error) { return "ws", nil },
+		func(string) error { return nil },
+		func(string) error {
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		},
+	)
+	fmt.Println(err, pings.Load() >= 3)
+
+	client, server := net.Pipe()
+	defer server.Close()
+	err = bnet.WithKeepAlive(
+		5*time.Millisecond,
+		func(net.Conn) error { return errors.New("pong not received") },
+		func() (net.Conn, error) { return client, nil },
+		net.Conn.Close,
+		func(conn net.Conn) error {
+			_, err := conn.Read(make([]byte, 1)) // blocks until interrupted
+			return err
+		},
+	)
+	fmt.Println(err)
+	fmt.Println(brago.IsReleaseFailure(err))
+
+	err = bnet.WithKeepAlive(
+		0,
+		func(string) error { return nil },
+		func() (string, error) {
+			fmt.Println("not reached")
+			return "ws", nil
+		},
+		func(string) error { return nil },
+		func(string) error { return nil },
+	)
+	fmt.Println(err)
+	// Output:
+	// <nil> true
+	// read pipe: i/o timeout
+	// pong not received
+	// false
+	// brago/net: non-positive keepalive interval 0s
+}
+
+func ExampleWithDeadline() {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	err := bnet.WithDeadline(client, 20*time.Millisecond, func(conn net.Conn) error {
+		// The server never answers.
+		_, err := conn.Read(make([]byte, 1))
+		return err
+	})
+	fmt.Println(errors.Is(err, os.ErrDeadlineExceeded))
+
+	// The deadline is gone, so the next read waits for the answer.
+	go io.WriteString(server, "late")
+	b := make([]byte, 4)
+	_, err = io.ReadFull(client, b)
+	fmt.Println(string(b), err)
+	// Output:
+	// true
+	// late <nil>
+}
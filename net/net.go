@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+/* Wrappers of brago for stdlib net package. */
+package net
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/thelissimus/brago"
+)
+
+// WithConnHalfClose closes the writing side of conn after use succeeds, if conn supports it (e.g.
+// [pkg/net.TCPConn]), so the peer sees EOF. Afterwards conn is closed.
+func WithConnHalfClose(conn net.Conn, use func(net.Conn) error) error {
+	return brago.WithResource(
+		func() (net.Conn, error) { return conn, nil },
+		func(conn net.Conn) error {
+			if err := use(conn); err != nil {
+				return err
+			}
+
+			if cw, ok := conn.(interface{ CloseWrite() error }); ok {
+				return cw.CloseWrite()
+			}
+			return nil
+		},
+	)
+}
+
+// WithDeadline sets the deadline of conn to d from now while use runs, so the I/O of use times out
+// with [pkg/os.ErrDeadlineExceeded]. Afterwards the deadline is cleared, so it does not affect the
+// later uses of conn, which is not closed.
+func WithDeadline(conn net.Conn, d time.Duration, use func(net.Conn) error) error {
+	return brago.Bracket(
+		func() (net.Conn, error) { return conn, conn.SetDeadline(time.Now().Add(d)) },
+		func(conn net.Conn) error { return conn.SetDeadline(time.Time{}) },
+		use,
+	)
+}
+
+// WithListenPacket is a wrapper for [pkg/net.ListenPacket].
+func WithListenPacket(network, address string, use func(net.PacketConn) error) error {
+	return brago.WithResource(
+		func() (net.PacketConn, error) { return net.ListenPacket(network, address) },
+		use,
+	)
+}
+
+// WithKeepAlive is like [brago.Bracket], but the resource is pinged every interval while use runs,
+// e.g. to keep a WebSocket connection alive. The pinging stops before the resource is released. If
+// a ping fails, the pinging stops and its error is joined with the error of use, as the failure
+// belongs to the use rather than to the release. Since use has no context to cancel, a failed ping
+// interrupts use by setting the deadline of the resource to now, if it has a SetDeadline method like
+// [pkg/net.Conn] does, which fails the pending I/O of use. The interval MUST be positive, otherwise
+// an error is returned before the resource is acquired.
+func WithKeepAlive[R any](interval time.Duration, ping func(R) error, acquire func() (R, error), release func(R) error, use func(R) error) error {
+	type keepAlive struct {
+		r    R
+		stop func() error
+	}
+
+	return brago.Bracket(
+		func() (keepAlive, error) {
+			if interval <= 0 {
+				// time.NewTicker would panic on the goroutine, out of reach of the caller.
+				return keepAlive{}, fmt.Errorf("brago/net: non-positive keepalive interval %v", interval)
+			}
+
+			r, err := acquire()
+			if err != nil {
+				return keepAlive{}, err
+			}
+
+			stop, done := make(chan struct{}), make(chan error, 1)
+			t := time.NewTicker(interval)
+			go func() {
+				defer t.Stop()
+
+				for {
+					select {
+					case <-t.C:
+						if err := ping(r); err != nil {
+							if d, ok := any(r).(interface{ SetDeadline(time.Time) error }); ok {
+								d.SetDeadline(time.Now())
+							}
+							done <- err
+							return
+						}
+					case <-stop:
+						done <- nil
+						return
+					}
+				}
+			}()
+
+			return keepAlive{r: r, stop: sync.OnceValue(func() error {
+				close(stop)
+				return <-done
+			})}, nil
+		},
+		func(k keepAlive) error {
+			// The pinging is stopped by use already, unless it panicked.
+			k.stop()
+			return release(k.r)
+		},
+		func(k keepAlive) error {
+			err := use(k.r)
+			return errors.Join(err, k.stop())
+		},
+	)
+}
@@ -1,7 +1,14 @@
 package os_test
 
 import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"time"
 
 	bos "github.com/thelissimus/brago/os"
 )
@@ -15,3 +22,363 @@ func ExampleWithOpen() {
 		// handle all the errors here
 	}
 }
+
+func ExampleWithStdout() {
+	f, err := os.CreateTemp("", "brago-example")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	orig := os.Stdout
+	err = bos.WithStdout(f, func() error {
+		fmt.Println("captured")
+		return nil
+	})
+	fmt.Println(err, os.Stdout == orig)
+
+	err = bos.WithStdout(f, func() error {
+		return errors.New("failed")
+	})
+	fmt.Println(err, os.Stdout == orig)
+
+	b, _ := os.ReadFile(f.Name())
+	fmt.Printf("%q\n", b)
+	// Output:
+	// <nil> true
+	// failed true
+	// "captured\n"
+}
+
+func ExampleWithLogFile() {
+	dir, err := os.MkdirTemp("", "brago-example")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "app.log")
+
+	for _, line := range []string{"first\n", "second\n"} {
+		err := bos.WithLogFile(name, func(f *os.File) error {
+			_, err := f.WriteString(line)
+			return err
+		})
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	b, _ := os.ReadFile(name)
+	fmt.Print(string(b))
+	// Output:
+	// first
+	// second
+}
+
+func ExampleWithPipe() {
+	var r, w *os.File
+	err := bos.WithPipe(func(pr *os.File, pw *os.File) error {
+		r, w = pr, pw
+		if _, err := io.WriteString(pw, "through the pipe"); err != nil {
+			return err
+		}
+		b := make([]byte, 16)
+		_, err := io.ReadFull(pr, b)
+		fmt.Println(string(b))
+		return err
+	})
+	fmt.Println(err)
+	fmt.Println(errors.Is(r.Close(), os.ErrClosed), errors.Is(w.Close(), os.ErrClosed))
+	// Output:
+	// through the pipe
+	// <nil>
+	// true true
+}
+
+func ExampleWithTempFilePath() {
+	var file *os.File
+	path, err := bos.WithTempFilePath("", "brago-example-*.txt", func(f *os.File) error {
+		file = f
+		_, err := f.WriteString("handed over")
+		return err
+	})
+	if path != "" {
+		defer os.Remove(path)
+	}
+	fmt.Println(err)
+
+	b, err := os.ReadFile(path)
+	fmt.Println(string(b), err)
+	fmt.Println(errors.Is(file.Close(), os.ErrClosed))
+	// Output:
+	// <nil>
+	// handed over <nil>
+	// true
+}
+
+func ExampleWithOpenGzip() {
+	f, err := os.CreateTemp("", "brago-example-*.gz")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer os.Remove(f.Name())
+	zw := gzip.NewWriter(f)
+	io.WriteString(zw, "decompressed")
+	zw.Close()
+	f.Close()
+
+	err = bos.WithOpenGzip(f.Name(), func(zr *gzip.Reader) error {
+		b, err := io.ReadAll(zr)
+		fmt.Println(string(b))
+		return err
+	})
+	fmt.Println(err)
+	// Output:
+	// decompressed
+	// <nil>
+}
+
+func ExampleWithCreateExcl() {
+	dir, err := os.MkdirTemp("", "brago-example")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "app.lock")
+
+	for i := 0; i < 2; i++ {
+		err := bos.WithCreateExcl(name, 0600, func(f *os.File) error {
+			fmt.Println("created")
+			return nil
+		})
+		fmt.Println(errors.Is(err, os.ErrExist))
+	}
+	// Output:
+	// created
+	// false
+	// true
+}
+
+func ExampleWithReadDir() {
+	dir, err := os.MkdirTemp("", "brago-example")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"b.txt", "c.txt", "a.txt"} {
+		os.WriteFile(filepath.Join(dir, name), nil, 0644)
+	}
+	os.Mkdir(filepath.Join(dir, "sub"), 0755)
+
+	err = bos.WithReadDir(dir, func(entries []os.DirEntry) error {
+		for _, e := range entries {
+			fmt.Println(e.Name(), e.IsDir())
+		}
+		return nil
+	})
+	fmt.Println(err)
+	// Output:
+	// a.txt false
+	// b.txt false
+	// c.txt false
+	// sub true
+	// <nil>
+}
+
+func ExampleWithTempSymlink() {
+	dir, err := os.MkdirTemp("", "brago-example")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	target := filepath.Join(dir, "target.txt")
+	os.WriteFile(target, []byte("pointed at"), 0644)
+
+	var link string
+	err = bos.WithTempSymlink(target, dir, "link-*.txt", func(linkPath string) error {
+		link = linkPath
+		dest, err := os.Readlink(linkPath)
+		fmt.Println(dest == target)
+		b, _ := os.ReadFile(linkPath)
+		fmt.Println(string(b))
+		return err
+	})
+	fmt.Println(err)
+
+	_, err = os.Lstat(link)
+	fmt.Println(errors.Is(err, os.ErrNotExist))
+	// Output:
+	// true
+	// pointed at
+	// <nil>
+	// true
+}
+
+func ExampleWithWatch() {
+	f, err := os.CreateTemp("", "brago-example-*.conf")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	var events <-chan string
+	err = bos.WithWatch(f.Name(), func(ev <-chan string) error {
+		events = ev
+		if err := os.WriteFile(f.Name(), []byte("reloaded = true"), 0644); err != nil {
+			return err
+		}
+
+		select {
+		case name := <-ev:
+			fmt.Println(name == f.Name())
+		case <-time.After(5 * time.Second):
+			fmt.Println("no event")
+		}
+		return nil
+	})
+	fmt.Println(err)
+
+	_, ok := <-events
+	fmt.Println(ok)
+	// Output:
+	// true
+	// <nil>
+	// false
+}
+
+func ExampleWithStdinBuffered() {
+	r, w, err := os.Pipe()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer r.Close()
+
+	io.WriteString(w, "first\nsecond\n")
+	w.Close()
+
+	err = bos.WithStdin(r, func() error {
+		return bos.WithStdinBuffered(func(br *bufio.Reader) error {
+			for {
+				line, err := br.ReadString('\n')
+				if err != nil {
+					return err
+				}
+				fmt.Print(line)
+			}
+		})
+	})
+	fmt.Println(err)
+	// Output:
+	// first
+	// second
+	// <nil>
+}
+
+func ExampleWithReopenRW() {
+	f, err := os.CreateTemp("", "brago-example-*.conf")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	f.WriteString("version = 1")
+	f.Close()
+	defer os.Remove(f.Name())
+
+	var read, written *os.File
+	inspect := func(f *os.File) error {
+		read = f
+		b, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		if string(b) != "version = 1" {
+			return errors.New("unknown version")
+		}
+		return nil
+	}
+	upgrade := func(f *os.File) error {
+		written = f
+		_, err := f.WriteAt([]byte("2"), int64(len("version = ")))
+		return err
+	}
+
+	err = bos.WithReopenRW(f.Name(), inspect, upgrade)
+	fmt.Println(err)
+	fmt.Println(errors.Is(read.Close(), os.ErrClosed), errors.Is(written.Close(), os.ErrClosed))
+
+	// The version is 2 now, so the file is not upgraded again.
+	written = nil
+	err = bos.WithReopenRW(f.Name(), inspect, upgrade)
+	fmt.Println(err)
+	fmt.Println(errors.Is(read.Close(), os.ErrClosed), written == nil)
+	// Output:
+	// <nil>
+	// true true
+	// unknown version
+	// true true
+}
+
+func ExampleWithStagingDir() {
+	dir, err := os.MkdirTemp("", "brago-example")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	site := filepath.Join(dir, "site")
+
+	write := func(content string) func(string) error {
+		return func(stageDir string) error {
+			return os.WriteFile(filepath.Join(stageDir, "index.html"), []byte(content), 0644)
+		}
+	}
+	fail := func(stageDir string) error {
+		if err := write("half-built")(stageDir); err != nil {
+			return err
+		}
+		return errors.New("build failed")
+	}
+	show := func() {
+		b, err := os.ReadFile(filepath.Join(site, "index.html"))
+		entries, _ := os.ReadDir(dir)
+		fmt.Printf("%q %v, %d entries\n", b, err == nil, len(entries))
+	}
+
+	fmt.Println(bos.WithStagingDir(site, fail))
+	_, err = os.Stat(site)
+	fmt.Println(errors.Is(err, os.ErrNotExist))
+
+	fmt.Println(bos.WithStagingDir(site, write("v1")))
+	show()
+
+	err = bos.WithStagingDir(site, write("v2"))
+	fmt.Println(errors.Is(err, os.ErrExist))
+	show()
+
+	fmt.Println(bos.WithStagingDirReplace(site, write("v2")))
+	show()
+
+	fmt.Println(bos.WithStagingDirReplace(site, fail))
+	show()
+	// Output:
+	// build failed
+	// true
+	// <nil>
+	// "v1" true, 1 entries
+	// true
+	// "v1" true, 1 entries
+	// <nil>
+	// "v2" true, 1 entries
+	// build failed
+	// "v2" true, 1 entries
+}
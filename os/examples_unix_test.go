@@ -0,0 +1,98 @@
+//go:build unix
+
+package os_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	bos "github.com/thelissimus/brago/os"
+)
+
+func ExampleWithFlock() {
+	name := filepath.Join(os.TempDir(), "brago-example-flock")
+	defer os.Remove(name)
+
+	// The writer creates the file under an exclusive lock.
+	err := bos.WithFlock(name, true, func(f *os.File) error {
+		_, err := f.WriteString("data")
+		return err
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	held := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		bos.WithFlock(name, false, func(f *os.File) error {
+			close(held)
+			<-done
+			return nil
+		})
+	}()
+	<-held
+
+	// A second reader gets its shared lock while the first one still holds its own.
+	err = bos.WithFlock(name, false, func(f *os.File) error {
+		fmt.Println("second reader holds the lock")
+
+		// A writer cannot get in while readers are holding the file.
+		g, err := os.OpenFile(name, os.O_RDWR, 0)
+		if err != nil {
+			return err
+		}
+		defer g.Close()
+		fmt.Println(syscall.Flock(int(g.Fd()), syscall.LOCK_EX|syscall.LOCK_NB))
+		return nil
+	})
+	close(done)
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// second reader holds the lock
+	// resource temporarily unavailable
+}
+
+func ExampleWithFlockTimeout() {
+	name := filepath.Join(os.TempDir(), "brago-example-flock-timeout")
+	defer os.Remove(name)
+
+	err := bos.WithFlockTimeout(name, time.Second, func(*os.File) error {
+		// Any other opening of the file cannot take the lock while it is held.
+		return bos.WithFlockTimeout(name, 50*time.Millisecond, func(*os.File) error {
+			fmt.Println("not reached")
+			return nil
+		})
+	})
+	fmt.Println(err)
+
+	err = bos.WithFlockTimeout(name, 50*time.Millisecond, func(*os.File) error { return nil })
+	fmt.Println(err)
+	// Output:
+	// brago/os: timed out waiting for the lock
+	// <nil>
+}
+
+func ExampleWithFd() {
+	var fds [2]int
+	if err := syscall.Pipe(fds[:]); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	err := bos.WithFd(fds[1], func(fd int) error {
+		_, err := syscall.Write(fd, []byte("raw"))
+		return err
+	})
+	fmt.Println(err)
+	fmt.Println(syscall.Close(fds[1]))
+	syscall.Close(fds[0])
+	// Output:
+	// <nil>
+	// bad file descriptor
+}
@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build unix
+
+package os
+
+import (
+	"syscall"
+
+	"github.com/thelissimus/brago"
+)
+
+// WithFd takes over the raw file descriptor, which is not wrapped in an [pkg/os.File], and closes
+// it with [pkg/syscall.Close] afterwards.
+func WithFd(fd int, use func(int) error) error {
+	return brago.Bracket(
+		func() (int, error) { return fd, nil },
+		syscall.Close,
+		use,
+	)
+}
@@ -4,7 +4,14 @@
 package os
 
 import (
+	"compress/gzip"
+	"errors"
+	"math/rand/v2"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/thelissimus/brago"
 )
@@ -23,3 +30,116 @@ func WithCreate(name string, use func(*os.File) error) error {
 func WithOpenFile(name string, flag int, perm os.FileMode, use func(*os.File) error) error {
 	return brago.WithResource(func() (*os.File, error) { return os.OpenFile(name, flag, perm) }, use)
 }
+
+// WithCreateExcl creates the file for writing and fails with [pkg/os.ErrExist] if it exists
+// already, which suits lock files and atomic creation.
+func WithCreateExcl(name string, perm os.FileMode, use func(*os.File) error) error {
+	return WithOpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm, use)
+}
+
+// WithLogFile opens the file for appending, creating it if needed. Afterwards the file is synced
+// and closed.
+func WithLogFile(name string, use func(*os.File) error) error {
+	return brago.Bracket(
+		func() (*os.File, error) { return os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) },
+		syncClose,
+		use,
+	)
+}
+
+// WithPipe is a wrapper for [pkg/os.Pipe]. Afterwards the writing end is closed, then the reading
+// end.
+func WithPipe(use func(r *os.File, w *os.File) error) error {
+	type pipe struct{ r, w *os.File }
+
+	return brago.Bracket(
+		func() (pipe, error) {
+			r, w, err := os.Pipe()
+			return pipe{r, w}, err
+		},
+		func(p pipe) error { return errors.Join(p.w.Close(), p.r.Close()) },
+		func(p pipe) error { return use(p.r, p.w) },
+	)
+}
+
+// WithTempFilePath is a wrapper for [pkg/os.CreateTemp]. Afterwards the file is closed but not
+// removed, its path is returned and the caller is responsible for removing it. The path is returned
+// even if use fails, so the file can be inspected or removed.
+func WithTempFilePath(dir, pattern string, use func(*os.File) error) (string, error) {
+	f, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", err
+	}
+
+	return f.Name(), brago.WithResource(func() (*os.File, error) { return f, nil }, use)
+}
+
+// WithOpenGzip opens the gzip compressed file and passes its decompressing reader to use.
+// Afterwards the reader is closed, then the file.
+func WithOpenGzip(name string, use func(*gzip.Reader) error) error {
+	return WithOpen(name, func(f *os.File) error {
+		return brago.WithResource(func() (*gzip.Reader, error) { return gzip.NewReader(f) }, use)
+	})
+}
+
+// WithReadDir reads all the entries of the directory sorted by filename, like [pkg/os.ReadDir],
+// closes the directory and then passes the entries to use.
+func WithReadDir(name string, use func([]os.DirEntry) error) error {
+	var entries []os.DirEntry
+	err := WithOpen(name, func(f *os.File) (err error) {
+		entries, err = f.ReadDir(-1)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	slices.SortFunc(entries, func(a, b os.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+	return use(entries)
+}
+
+// WithTempSymlink creates a symbolic link to target with a unique name in linkDir and passes its
+// path to use. The name is built from pattern like [pkg/os.CreateTemp] does. Afterwards the link is
+// removed.
+func WithTempSymlink(target, linkDir, pattern string, use func(linkPath string) error) error {
+	return brago.Bracket(
+		func() (string, error) { return tempSymlink(target, linkDir, pattern) },
+		os.Remove,
+		use,
+	)
+}
+
+// WithReopenRW opens the file read-only for useRead and closes it, then opens it again for reading
+// and writing for useWrite and closes it. The write phase is skipped if the read phase fails, so
+// the file can be inspected before it is modified.
+func WithReopenRW(name string, useRead func(*os.File) error, useWrite func(*os.File) error) error {
+	if err := WithOpen(name, useRead); err != nil {
+		return err
+	}
+	return WithOpenFile(name, os.O_RDWR, 0, useWrite)
+}
+
+func tempSymlink(target, dir, pattern string) (string, error) {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	prefix, suffix := pattern, ""
+	if i := strings.LastIndex(pattern, "*"); i >= 0 {
+		prefix, suffix = pattern[:i], pattern[i+1:]
+	}
+
+	for try := 0; ; try++ {
+		name := filepath.Join(dir, prefix+strconv.FormatUint(uint64(rand.Uint32()), 10)+suffix)
+		err := os.Symlink(target, name)
+		if err == nil {
+			return name, nil
+		}
+		if !errors.Is(err, os.ErrExist) || try == 10000 {
+			return "", err
+		}
+	}
+}
+
+func syncClose(f *os.File) error {
+	return errors.Join(f.Sync(), f.Close())
+}
@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build unix
+
+package os
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/thelissimus/brago"
+)
+
+// WithFlock opens the file and holds an advisory lock on it while use runs. If exclusive is true
+// the lock is taken with LOCK_EX and the file is opened for reading and writing, creating it if
+// needed. Otherwise the lock is taken with LOCK_SH and the file is opened read-only, so any number
+// of readers may hold it at the same time. The lock is released and the file is closed afterwards.
+func WithFlock(name string, exclusive bool, use func(*os.File) error) error {
+	flag, how := os.O_RDONLY, syscall.LOCK_SH
+	if exclusive {
+		flag, how = os.O_RDWR|os.O_CREATE, syscall.LOCK_EX
+	}
+
+	return brago.Bracket(
+		func() (*os.File, error) { return openLocked(name, flag, how) },
+		unlockClose,
+		use,
+	)
+}
+
+// ErrLockTimeout is returned by [WithFlockTimeout] if the lock is not taken in time.
+var ErrLockTimeout = errors.New("brago/os: timed out waiting for the lock")
+
+// flockPoll is how often [WithFlockTimeout] tries to take the lock.
+const flockPoll = 10 * time.Millisecond
+
+// WithFlockTimeout is like [WithFlock] with exclusive set, but instead of blocking until the lock
+// is free it gives up after timeout and returns [ErrLockTimeout].
+func WithFlockTimeout(name string, timeout time.Duration, use func(*os.File) error) error {
+	return brago.Bracket(
+		func() (*os.File, error) {
+			f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0644)
+			if err != nil {
+				return nil, err
+			}
+
+			deadline := time.Now().Add(timeout)
+			for {
+				err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+				if err == nil {
+					return f, nil
+				}
+				if !errors.Is(err, syscall.EWOULDBLOCK) {
+					// MUST NOT leak the file if the lock cannot be taken!
+					return nil, errors.Join(err, f.Close())
+				}
+				if time.Now().After(deadline) {
+					return nil, errors.Join(ErrLockTimeout, f.Close())
+				}
+				time.Sleep(min(flockPoll, time.Until(deadline)))
+			}
+		},
+		unlockClose,
+		use,
+	)
+}
+
+func openLocked(name string, flag int, how int) (*os.File, error) {
+	f, err := os.OpenFile(name, flag, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = syscall.Flock(int(f.Fd()), how); err != nil {
+		// MUST NOT leak the file if the lock cannot be taken!
+		return nil, errors.Join(err, f.Close())
+	}
+
+	return f, nil
+}
+
+func unlockClose(f *os.File) error {
+	return errors.Join(syscall.Flock(int(f.Fd()), syscall.LOCK_UN), f.Close())
+}
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package os
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/thelissimus/brago"
+)
+
+// WithStagingDir creates a staging directory next to finalDir and passes its path to use, which
+// populates it. If use succeeds, the staging directory is renamed to finalDir, so the directory is
+// published all at once. Otherwise it is removed with everything in it and finalDir is untouched.
+// If finalDir already exists, the staging directory is removed and an error matching
+// [pkg/io/fs.ErrExist] is returned. See [WithStagingDirReplace] to replace it instead. The staging
+// directory is created by [pkg/os.MkdirTemp], so its mode is 0700 until use changes it.
+func WithStagingDir(finalDir string, use func(stageDir string) error) error {
+	return withStagingDir(finalDir, false, use)
+}
+
+// WithStagingDirReplace is like [WithStagingDir], but an existing finalDir is replaced by the
+// staging directory and removed. The replacement takes two renames, so finalDir is briefly missing.
+// If the second rename fails, the original finalDir is put back.
+func WithStagingDirReplace(finalDir string, use func(stageDir string) error) error {
+	return withStagingDir(finalDir, true, use)
+}
+
+func withStagingDir(finalDir string, replace bool, use func(string) error) error {
+	finalDir = filepath.Clean(finalDir)
+	pattern := "." + filepath.Base(finalDir) + ".stage-*"
+
+	return brago.BracketCommit(
+		func() (string, error) { return os.MkdirTemp(filepath.Dir(finalDir), pattern) },
+		func(string) error {
+			if replace {
+				return nil
+			}
+			if _, err := os.Lstat(finalDir); err == nil {
+				return &fs.PathError{Op: "publish", Path: finalDir, Err: fs.ErrExist}
+			}
+			return nil
+		},
+		func(stageDir string) error {
+			if err := publish(stageDir, finalDir, replace); err != nil {
+				// MUST NOT leave the staging directory behind if it cannot be published!
+				return errors.Join(err, os.RemoveAll(stageDir))
+			}
+			return nil
+		},
+		os.RemoveAll,
+		use,
+	)
+}
+
+// publish renames stageDir to finalDir. If replace is true, an existing finalDir is moved out of the
+// way first and removed afterwards.
+func publish(stageDir, finalDir string, replace bool) error {
+	if !replace {
+		return os.Rename(stageDir, finalDir)
+	}
+
+	old, err := os.MkdirTemp(filepath.Dir(finalDir), "."+filepath.Base(finalDir)+".old-*")
+	if err != nil {
+		return err
+	}
+	backup := filepath.Join(old, "dir")
+
+	if err = os.Rename(finalDir, backup); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return errors.Join(err, os.Remove(old))
+		}
+		// There is nothing to replace.
+		return errors.Join(os.Rename(stageDir, finalDir), os.Remove(old))
+	}
+
+	if err = os.Rename(stageDir, finalDir); err != nil {
+		return errors.Join(err, os.Rename(backup, finalDir), os.Remove(old))
+	}
+	return os.RemoveAll(old)
+}
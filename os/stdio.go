@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package os
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/thelissimus/brago"
+)
+
+// WithStdout replaces [pkg/os.Stdout] with w while use runs and restores the original afterwards.
+// It mutates process-global state, so it must not be used concurrently.
+func WithStdout(w *os.File, use func() error) error {
+	return withReplaced(&os.Stdout, w, use)
+}
+
+// WithStderr replaces [pkg/os.Stderr] with w while use runs and restores the original afterwards.
+// It mutates process-global state, so it must not be used concurrently.
+func WithStderr(w *os.File, use func() error) error {
+	return withReplaced(&os.Stderr, w, use)
+}
+
+// WithStdin replaces [pkg/os.Stdin] with r while use runs and restores the original afterwards.
+// It mutates process-global state, so it must not be used concurrently.
+func WithStdin(r *os.File, use func() error) error {
+	return withReplaced(&os.Stdin, r, use)
+}
+
+// WithStdinBuffered passes a buffered reader of [pkg/os.Stdin] to use. The stdin is not closed, as
+// it belongs to the process. Reaching the end of the input is not an error, so an [pkg/io.EOF]
+// returned by use is dropped. Any other error of reading the stdin is returned, even if use ignored
+// it.
+func WithStdinBuffered(use func(*bufio.Reader) error) error {
+	r := &stickyReader{r: os.Stdin}
+
+	err := use(bufio.NewReader(r))
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	if err == nil {
+		err = r.err
+	}
+	return err
+}
+
+// stickyReader remembers the first error of the reader other than io.EOF.
+type stickyReader struct {
+	r   io.Reader
+	err error
+}
+
+func (s *stickyReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if err != nil && err != io.EOF && s.err == nil {
+		s.err = err
+	}
+	return n, err
+}
+
+func withReplaced(v **os.File, f *os.File, use func() error) error {
+	return brago.Bracket(
+		func() (*os.File, error) {
+			orig := *v
+			*v = f
+			return orig, nil
+		},
+		func(orig *os.File) error {
+			*v = orig
+			return nil
+		},
+		func(*os.File) error { return use() },
+	)
+}
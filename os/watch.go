@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package os
+
+import (
+	"os"
+	"time"
+
+	"github.com/thelissimus/brago"
+)
+
+// watchInterval is how often [WithWatch] polls the file.
+const watchInterval = 100 * time.Millisecond
+
+// WithWatch watches the file for modifications while use runs. The name of the file is sent on
+// events whenever its modification time or size changes, or it is removed or created again. The
+// file is watched by polling its status, so events are coalesced and may be delayed. Afterwards the
+// watching stops and events is closed.
+func WithWatch(name string, use func(events <-chan string) error) error {
+	type watcher struct {
+		events chan string
+		stop   chan struct{}
+		done   chan struct{}
+	}
+
+	return brago.Bracket(
+		func() (watcher, error) {
+			last, err := os.Stat(name)
+			if err != nil {
+				return watcher{}, err
+			}
+
+			w := watcher{
+				events: make(chan string, 1),
+				stop:   make(chan struct{}),
+				done:   make(chan struct{}),
+			}
+			go func() {
+				t := time.NewTicker(watchInterval)
+				defer t.Stop()
+				defer close(w.done)
+
+				for {
+					select {
+					case <-t.C:
+						info, _ := os.Stat(name)
+						if changed(last, info) {
+							select {
+							case w.events <- name:
+							default: // an event is pending already
+							}
+						}
+						last = info
+					case <-w.stop:
+						return
+					}
+				}
+			}()
+
+			return w, nil
+		},
+		func(w watcher) error {
+			close(w.stop)
+			<-w.done
+			close(w.events)
+			return nil
+		},
+		func(w watcher) error { return use(w.events) },
+	)
+}
+
+func changed(a, b os.FileInfo) bool {
+	if a == nil || b == nil {
+		return a != b
+	}
+	return !a.ModTime().Equal(b.ModTime()) || a.Size() != b.Size()
+}
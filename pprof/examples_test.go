@@ -0,0 +1,49 @@
+package pprof_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/thelissimus/brago/pprof"
+)
+
+func ExampleWithCPUProfile() {
+	var buf bytes.Buffer
+	err := pprof.WithCPUProfile(&buf, func() error {
+		n := 0
+		for i := 0; i < 1e7; i++ {
+			n += i
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(buf.Len() > 0)
+	// Output: true
+}
+
+type closeTracker struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed = true
+	return nil
+}
+
+func ExampleWithCPUProfile_alreadyRunning() {
+	err := pprof.WithCPUProfile(io.Discard, func() error {
+		// Only one profile can run at a time, the writer of the second one is closed anyway.
+		w := &closeTracker{}
+		err := pprof.WithCPUProfile(w, func() error { return nil })
+		fmt.Println(err, w.closed)
+		return nil
+	})
+	fmt.Println(err)
+	// Output:
+	// cpu profiling already in use true
+	// <nil>
+}
@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+/* Wrappers of brago for stdlib runtime/pprof package. */
+package pprof
+
+import (
+	"errors"
+	"io"
+	"runtime/pprof"
+
+	"github.com/thelissimus/brago"
+)
+
+// WithCPUProfile is a wrapper for [pkg/runtime/pprof.StartCPUProfile]. The profile of use is
+// written to w. Afterwards the profile is stopped and w is closed if it implements io.Closer. If
+// the profile cannot be started, w is closed as well.
+func WithCPUProfile(w io.Writer, use func() error) error {
+	return brago.Bracket(
+		func() (io.Writer, error) {
+			if err := pprof.StartCPUProfile(w); err != nil {
+				// MUST NOT leak w if the profile cannot be started!
+				return nil, errors.Join(err, closeWriter(w))
+			}
+			return w, nil
+		},
+		func(w io.Writer) error {
+			pprof.StopCPUProfile()
+			return closeWriter(w)
+		},
+		func(io.Writer) error { return use() },
+	)
+}
+
+func closeWriter(w io.Writer) error {
+	if c, ok := w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
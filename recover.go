@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package brago
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// PanicError is the error of a recovered panic. It carries the value passed to panic and the stack
+// trace of the panicking goroutine.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("brago: recovered panic: %v", e.Value)
+}
+
+// BracketRecover is like [Bracket], but a panic in use is recovered and returned as a [PanicError],
+// after the resource is released.
+func BracketRecover[R any](acquire func() (R, error), release func(R) error, use func(R) error) error {
+	return Bracket(acquire, release, recoverUse(use))
+}
+
+// recoverUse wraps use, so a panic in it is returned as an error and the resource is released.
+func recoverUse[R any](use func(R) error) func(R) error {
+	return func(r R) error {
+		return recovered(func() error { return use(r) })
+	}
+}
+
+func recovered(f func() error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = &PanicError{Value: p, Stack: stack()}
+		}
+	}()
+	return f()
+}
+
+func stack() []byte {
+	buf := make([]byte, 4096)
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package brago
+
+import "time"
+
+// Report is the breakdown of a bracket run by [BracketReport]. The phases which did not run have
+// zero durations and nil errors. The error of the release is a [ReleaseError].
+type Report struct {
+	AcquireDuration time.Duration
+	UseDuration     time.Duration
+	ReleaseDuration time.Duration
+
+	AcquireErr error
+	UseErr     error
+	ReleaseErr error
+}
+
+// Err returns the error [Bracket] would have returned for the same run.
+func (r Report) Err() error {
+	if r.AcquireErr != nil {
+		return r.AcquireErr
+	}
+	return DefaultCombinePolicy().Combine(r.UseErr, r.ReleaseErr)
+}
+
+// BracketReport is like [Bracket], but instead of the error it returns a [Report] of how long each
+// phase took and how it failed. It is meant for diagnosing and benchmarking resource-heavy code.
+func BracketReport[R any](acquire func() (R, error), release func(R) error, use func(R) error) Report {
+	var rep Report
+
+	start := time.Now()
+	r, err := acquire()
+	rep.AcquireDuration = time.Since(start)
+	if err != nil {
+		rep.AcquireErr = err
+		return rep
+	}
+
+	start = time.Now()
+	rep.UseErr = use(r)
+	rep.UseDuration = time.Since(start)
+
+	start = time.Now()
+	rep.ReleaseErr = released(release(r))
+	rep.ReleaseDuration = time.Since(start)
+
+	return rep
+}
@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package brago
+
+import "io"
+
+// Resource is a resource which is acquired, passed to use and released when it is called.
+type Resource[R any] func(use func(R) error) error
+
+// NewResource is used to build the [Resource] from its acquire and release.
+func NewResource[R any](acquire func() (R, error), release func(R) error) Resource[R] {
+	return func(use func(R) error) error {
+		return Bracket(acquire, release, use)
+	}
+}
+
+// FromCloser is used to build the [Resource] which implements io.Closer. It is released by Close.
+func FromCloser[R io.Closer](acquire func() (R, error)) Resource[R] {
+	return func(use func(R) error) error {
+		return WithResource(acquire, use)
+	}
+}
+
+// Map transforms the acquired resource with f before passing it to use. The original resource is
+// still the one which is released.
+func Map[R, S any](r Resource[R], f func(R) S) Resource[S] {
+	return func(use func(S) error) error {
+		return r(func(v R) error { return use(f(v)) })
+	}
+}
+
+// FlatMap acquires the resource of f, which depends on the resource of r. The resources are
+// released in reverse order of acquisition.
+func FlatMap[R, S any](r Resource[R], f func(R) Resource[S]) Resource[S] {
+	return func(use func(S) error) error {
+		return r(func(v R) error { return f(v)(use) })
+	}
+}
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package brago
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryConfig configures the acquisition retries of [BracketRetryBackoff].
+type RetryConfig struct {
+	// InitialDelay is the delay after the first failed attempt.
+	InitialDelay time.Duration
+	// MaxDelay caps the delay between attempts, including the jitter. Zero means no cap.
+	MaxDelay time.Duration
+	// Multiplier scales the delay after every failed attempt. Values below 1 are treated as 1.
+	Multiplier float64
+	// Jitter randomizes every delay by up to the given fraction of it, e.g. 0.1 for ±10%.
+	Jitter float64
+	// MaxAttempts is the total number of attempts. Values below 1 are treated as 1.
+	MaxAttempts int
+	// Sleep waits for the delay or until the context is done. Nil means a timer based sleep. It is
+	// mostly useful to fake the clock in tests.
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// BracketRetryBackoff is like [Bracket], but a failed acquire is retried with an exponential
+// backoff as configured by cfg. The waiting between attempts is interrupted once ctx is done. The
+// error of the last attempt is returned if all of them fail.
+func BracketRetryBackoff[R any](ctx context.Context, cfg RetryConfig, acquire func() (R, error), release func(R) error, use func(R) error) error {
+	return Bracket(func() (R, error) { return retryBackoff(ctx, cfg, acquire) }, release, use)
+}
+
+func retryBackoff[R any](ctx context.Context, cfg RetryConfig, acquire func() (R, error)) (R, error) {
+	sleep := cfg.Sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+	multiplier := max(cfg.Multiplier, 1)
+	attempts := max(cfg.MaxAttempts, 1)
+
+	delay := cfg.InitialDelay
+	for i := 1; ; i++ {
+		r, err := acquire()
+		if err == nil || i == attempts {
+			return r, err
+		}
+
+		d := delay
+		if cfg.Jitter > 0 {
+			d += time.Duration((rand.Float64()*2 - 1) * cfg.Jitter * float64(d))
+		}
+		if cfg.MaxDelay > 0 {
+			d = min(d, cfg.MaxDelay)
+		}
+		if serr := sleep(ctx, d); serr != nil {
+			return r, errors.Join(err, serr)
+		}
+
+		delay = time.Duration(float64(delay) * multiplier)
+		if cfg.MaxDelay > 0 {
+			delay = min(delay, cfg.MaxDelay)
+		}
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// BracketUseRetry is like [Bracket], but if use fails, the resource is released and use is retried
+// on a freshly acquired one, up to attempts times in total. It suits idempotent operations, e.g.
+// retrying a query on a new connection. If every attempt fails, their errors are joined. A failure
+// of acquire is not retried.
+func BracketUseRetry[R any](attempts int, acquire func() (R, error), release func(R) error, use func(R) error) error {
+	var errs []error
+	for i := 0; i < max(attempts, 1); i++ {
+		r, err := acquire()
+		if err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+
+		used := false
+		err = Bracket(
+			func() (R, error) { return r, nil },
+			release,
+			func(r R) error {
+				if err := use(r); err != nil {
+					return err
+				}
+				used = true
+				return nil
+			},
+		)
+		if used {
+			return err
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,40 @@
+package safe_test
+
+import (
+	"fmt"
+
+	"github.com/thelissimus/brago/safe"
+)
+
+type closer struct{}
+
+func (closer) Close() error {
+	fmt.Println("closed")
+	return nil
+}
+
+func ExampleBracket() {
+	err := safe.Bracket(
+		func() (string, error) { return "conn", nil },
+		func(r string) error {
+			fmt.Println("released", r)
+			return nil
+		},
+		func(string) error { panic("boom") },
+	)
+	fmt.Println(err)
+	// Output:
+	// released conn
+	// brago: recovered panic: boom
+}
+
+func ExampleWithResource() {
+	err := safe.WithResource(
+		func() (closer, error) { return closer{}, nil },
+		func(closer) error { panic("boom") },
+	)
+	fmt.Println(err)
+	// Output:
+	// closed
+	// brago: recovered panic: boom
+}
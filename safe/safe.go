@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+/*
+Panic-safe variants of the brago brackets. They are drop-in replacements for the brackets of the
+same name in brago, but a panic in use is recovered and returned as a [brago.PanicError] after the
+resource is released.
+*/
+package safe
+
+import (
+	"io"
+
+	"github.com/thelissimus/brago"
+)
+
+// Bracket is like [brago.Bracket], but panic-safe.
+func Bracket[R any](acquire func() (R, error), release func(R) error, use func(R) error) error {
+	return brago.BracketRecover(acquire, release, use)
+}
+
+// WithResource is like [brago.WithResource], but panic-safe.
+func WithResource[R io.Closer](acquire func() (R, error), use func(R) error) error {
+	return Bracket(
+		acquire,
+		func(r R) error { return r.Close() },
+		use,
+	)
+}
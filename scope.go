@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package brago
+
+import (
+	"errors"
+	"sync"
+)
+
+// Scope collects the releases of resources acquired during its lifetime and runs them when it is
+// closed. It is safe for concurrent use.
+type Scope struct {
+	mu       sync.Mutex
+	releases []func() error
+}
+
+// Defer registers the release to run when the scope is closed.
+func (s *Scope) Defer(release func() error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.releases = append(s.releases, release)
+}
+
+// Close runs the registered releases in reverse order and joins their errors. Every release runs
+// even if a previous one fails.
+func (s *Scope) Close() error {
+	s.mu.Lock()
+	releases := s.releases
+	s.releases = nil
+	s.mu.Unlock()
+
+	errs := make([]error, 0, len(releases))
+	for i := len(releases) - 1; i >= 0; i-- {
+		errs = append(errs, releases[i]())
+	}
+	return errors.Join(errs...)
+}
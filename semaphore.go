@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package brago
+
+// NewSemaphore is used to create a semaphore of n permits for [WithPermit].
+func NewSemaphore(n int) chan struct{} {
+	return make(chan struct{}, n)
+}
+
+// WithPermit takes a permit of the semaphore, blocking until one is available, and runs use. The
+// permit is returned afterwards exactly once, even if use panics, so at most cap(sem) uses run at
+// the same time.
+func WithPermit(sem chan struct{}, use func() error) error {
+	sem <- struct{}{}
+	// Unlike the release of a bracket, the permit MUST be returned even if use panics, otherwise
+	// the semaphore would shrink for good.
+	defer func() { <-sem }()
+
+	return use()
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package brago
+
+import "sync"
+
+// SingleflightBracket is a [Bracket] which shares the resource between concurrent uses of the same
+// key. The first Use of a key acquires the resource, the concurrent ones wait for it and use it at
+// the same time, and the last one to finish releases it. A Use which comes after the release
+// acquires the resource anew. It is safe for concurrent use.
+type SingleflightBracket[R any] struct {
+	acquire func(key string) (R, error)
+	release func(R) error
+
+	mu      sync.Mutex
+	flights map[string]*flight[R]
+}
+
+// flight is the resource of a key shared by the uses in flight.
+type flight[R any] struct {
+	ready chan struct{}
+	r     R
+	err   error
+	users int
+}
+
+// NewSingleflight is used to create the [SingleflightBracket] of the resource.
+func NewSingleflight[R any](acquire func(key string) (R, error), release func(R) error) *SingleflightBracket[R] {
+	return &SingleflightBracket[R]{
+		acquire: acquire,
+		release: release,
+		flights: make(map[string]*flight[R]),
+	}
+}
+
+// Use is like [Bracket] with the shared resource of key. If acquire fails, every Use waiting for
+// it fails with the same error. Only the Use which releases the resource gets the error of the
+// release.
+func (s *SingleflightBracket[R]) Use(key string, use func(R) error) error {
+	return Bracket(
+		func() (*flight[R], error) { return s.join(key) },
+		func(f *flight[R]) error { return s.leave(key, f) },
+		func(f *flight[R]) error { return use(f.r) },
+	)
+}
+
+func (s *SingleflightBracket[R]) join(key string) (*flight[R], error) {
+	s.mu.Lock()
+	if f, ok := s.flights[key]; ok {
+		f.users++
+		s.mu.Unlock()
+
+		<-f.ready
+		if f.err != nil {
+			return nil, f.err
+		}
+		return f, nil
+	}
+
+	f := &flight[R]{ready: make(chan struct{}), users: 1}
+	s.flights[key] = f
+	s.mu.Unlock()
+
+	f.r, f.err = s.acquire(key)
+	if f.err != nil {
+		// The waiters get the error, the following uses try to acquire the resource again.
+		s.mu.Lock()
+		delete(s.flights, key)
+		s.mu.Unlock()
+	}
+	close(f.ready)
+
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f, nil
+}
+
+func (s *SingleflightBracket[R]) leave(key string, f *flight[R]) error {
+	s.mu.Lock()
+	f.users--
+	last := f.users == 0
+	if last {
+		delete(s.flights, key)
+	}
+	s.mu.Unlock()
+
+	if !last {
+		return nil
+	}
+	return s.release(f.r)
+}
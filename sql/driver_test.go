@@ -0,0 +1,173 @@
+package sql_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// fakeDriver is a database driver which records what is done with it. The behavior of connections
+// is controlled with words in the DSN, e.g. "noping" makes every ping fail.
+type fakeDriver struct{}
+
+func init() {
+	sql.Register("fake", fakeDriver{})
+}
+
+var events struct {
+	sync.Mutex
+	log []string
+}
+
+func record(format string, args ...any) {
+	events.Lock()
+	defer events.Unlock()
+	events.log = append(events.log, fmt.Sprintf(format, args...))
+}
+
+// printEvents prints and forgets the recorded events.
+func printEvents() {
+	events.Lock()
+	defer events.Unlock()
+	for _, e := range events.log {
+		fmt.Println(e)
+	}
+	events.log = nil
+}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	record("open")
+	return &fakeConn{opts: strings.Fields(dsn)}, nil
+}
+
+type fakeConn struct {
+	opts []string
+}
+
+func (c *fakeConn) has(opt string) bool {
+	return slices.Contains(c.opts, opt)
+}
+
+func (c *fakeConn) Ping(_ context.Context) error {
+	record("ping")
+	if c.has("noping") {
+		return errors.New("ping failed")
+	}
+	return nil
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{query: query}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return (&fakeStmt{query: query}).Exec(values(args))
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return (&fakeStmt{query: query}).Query(values(args))
+}
+
+func values(args []driver.NamedValue) []driver.Value {
+	vs := make([]driver.Value, len(args))
+	for i, a := range args {
+		vs[i] = a.Value
+	}
+	return vs
+}
+
+func (c *fakeConn) Close() error {
+	record("close")
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	record("begin")
+	return fakeTx{}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error {
+	record("commit")
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	record("rollback")
+	return nil
+}
+
+// fakeStmt answers queries of the form "rows N" with N rows of a single column counting from 1.
+// With "rows N fail" the rows end with an error instead.
+type fakeStmt struct {
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	record("stmt close")
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+// Exec affects a single row, unless one of the arguments is "fail".
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if len(args) == 0 {
+		record("exec %s", s.query)
+	} else {
+		record("exec %s %v", s.query, args)
+	}
+
+	if slices.Contains(args, driver.Value("fail")) {
+		return nil, errors.New("constraint violated")
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	f := strings.Fields(s.query)
+	if len(f) < 2 || f[0] != "rows" {
+		return nil, fmt.Errorf("unknown query %q", s.query)
+	}
+	n, err := strconv.Atoi(f[1])
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{n: n, fail: slices.Contains(f, "fail")}, nil
+}
+
+type fakeRows struct {
+	i, n int
+	fail bool
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"n"}
+}
+
+func (r *fakeRows) Close() error {
+	record("rows close")
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.i == r.n {
+		if r.fail {
+			return errors.New("connection lost")
+		}
+		return io.EOF
+	}
+	r.i++
+	dest[0] = int64(r.i)
+	return nil
+}
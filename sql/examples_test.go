@@ -0,0 +1,195 @@
+package sql_test
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	bsql "github.com/thelissimus/brago/sql"
+)
+
+func ExampleWithOpen() {
+	err := bsql.WithOpen("fake", "", func(db *sql.DB) error {
+		record("use")
+		return nil
+	})
+	fmt.Println(err)
+	printEvents()
+
+	err = bsql.WithOpen("fake", "noping", func(db *sql.DB) error {
+		record("use")
+		return nil
+	})
+	fmt.Println(err)
+	printEvents()
+	// Output:
+	// <nil>
+	// open
+	// ping
+	// use
+	// close
+	// ping failed
+	// open
+	// ping
+	// close
+}
+
+func ExampleWithEachRow() {
+	db, err := sql.Open("fake", "")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	rows, err := db.Query("rows 3 fail")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	err = bsql.WithEachRow(
+		rows,
+		func(rows *sql.Rows) (n int, err error) {
+			err = rows.Scan(&n)
+			return n, err
+		},
+		func(n int) error {
+			fmt.Println("row", n)
+			return nil
+		},
+	)
+	fmt.Println(err)
+	db.Close()
+	printEvents()
+	// Output:
+	// row 1
+	// row 2
+	// row 3
+	// connection lost
+	// open
+	// rows close
+	// close
+}
+
+func ExampleWithSavepoint() {
+	db, err := sql.Open("fake", "")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	err = bsql.WithSavepoint(tx, "sp1", func(tx *sql.Tx) error {
+		_, err := tx.Exec("INSERT 1")
+		return err
+	})
+	fmt.Println(err)
+	err = bsql.WithSavepoint(tx, "sp2", func(tx *sql.Tx) error {
+		return errors.New("invalid data")
+	})
+	fmt.Println(err)
+	tx.Commit()
+	db.Close()
+	printEvents()
+	// Output:
+	// <nil>
+	// invalid data
+	// open
+	// begin
+	// exec SAVEPOINT sp1
+	// exec INSERT 1
+	// exec RELEASE SAVEPOINT sp1
+	// exec SAVEPOINT sp2
+	// exec ROLLBACK TO SAVEPOINT sp2
+	// commit
+	// close
+}
+
+func ExampleWithPrepareExec() {
+	db, err := sql.Open("fake", "")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	err = bsql.WithPrepareExec(db, "INSERT", []any{"gopher"}, func(res sql.Result) error {
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("expected 2 rows, got %d", n)
+	})
+	fmt.Println(err)
+	db.Close()
+	printEvents()
+	// Output:
+	// expected 2 rows, got 1
+	// open
+	// exec INSERT [gopher]
+	// stmt close
+	// close
+}
+
+func ExampleWithTunedDB() {
+	cfg := bsql.PoolConfig{MaxOpenConns: 8, MaxIdleConns: 2, ConnMaxLifetime: time.Hour}
+	err := bsql.WithTunedDB("fake", "", cfg, func(db *sql.DB) error {
+		fmt.Println(db.Stats().MaxOpenConnections)
+		return nil
+	})
+	fmt.Println(err)
+	// Output:
+	// 8
+	// <nil>
+}
+
+func ExampleWithBatchInsert() {
+	db, err := sql.Open("fake", "")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	rows := [][]any{{"gopher"}, {"fail"}, {"ferris"}}
+	err = bsql.WithBatchInsert(db, "INSERT", rows, func(inserted int64) error {
+		fmt.Println("inserted", inserted)
+		return nil
+	})
+	fmt.Println(err)
+	db.Close()
+	printEvents()
+	// Output:
+	// constraint violated
+	// open
+	// begin
+	// exec INSERT [gopher]
+	// exec INSERT [fail]
+	// stmt close
+	// rollback
+	// close
+}
+
+func ExampleWithMigrationLock() {
+	db, err := sql.Open("fake", "")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	err = bsql.WithMigrationLock(db, 42, func() error {
+		return errors.New("migration failed")
+	})
+	fmt.Println(err)
+	db.Close()
+	printEvents()
+	// Output:
+	// migration failed
+	// open
+	// exec SELECT pg_advisory_lock($1) [42]
+	// exec SELECT pg_advisory_unlock($1) [42]
+	// close
+}
@@ -0,0 +1,221 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+/* Wrappers of brago for stdlib database/sql package. */
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/thelissimus/brago"
+)
+
+// WithOpen is a wrapper for [pkg/database/sql.Open]. Since sql.Open does not connect, the database
+// is pinged before use runs. If the ping fails use is not run and the database is closed.
+func WithOpen(driver, dsn string, use func(*sql.DB) error) error {
+	return brago.WithResource(
+		func() (*sql.DB, error) {
+			db, err := sql.Open(driver, dsn)
+			if err != nil {
+				return nil, err
+			}
+
+			if err = db.Ping(); err != nil {
+				// MUST NOT leak the database if it is unreachable!
+				return nil, errors.Join(err, db.Close())
+			}
+
+			return db, nil
+		},
+		use,
+	)
+}
+
+// WithEachRow scans every row with scan and passes the result to use. Afterwards the error of the
+// iteration is checked and the rows are closed.
+func WithEachRow[T any](rows *sql.Rows, scan func(*sql.Rows) (T, error), use func(T) error) error {
+	return brago.WithResource(
+		func() (*sql.Rows, error) { return rows, nil },
+		func(rows *sql.Rows) error {
+			for rows.Next() {
+				v, err := scan(rows)
+				if err != nil {
+					return err
+				}
+
+				if err = use(v); err != nil {
+					return err
+				}
+			}
+			return rows.Err()
+		},
+	)
+}
+
+// WithSavepoint runs use in a savepoint of tx. If use succeeds the savepoint is released, otherwise
+// tx is rolled back to the savepoint. The statements are SAVEPOINT, RELEASE SAVEPOINT and ROLLBACK
+// TO SAVEPOINT, which are understood by PostgreSQL, MySQL and SQLite. The name is not quoted, so it
+// MUST be a trusted identifier.
+func WithSavepoint(tx *sql.Tx, name string, use func(*sql.Tx) error) error {
+	if _, err := tx.Exec("SAVEPOINT " + name); err != nil {
+		return err
+	}
+
+	if err := use(tx); err != nil {
+		_, rerr := tx.Exec("ROLLBACK TO SAVEPOINT " + name)
+		return errors.Join(err, rerr)
+	}
+
+	_, err := tx.Exec("RELEASE SAVEPOINT " + name)
+	return err
+}
+
+// WithPrepareExec prepares the query, executes it once with args and passes the result to use.
+// Afterwards the statement is closed.
+func WithPrepareExec(db *sql.DB, query string, args []any, use func(sql.Result) error) error {
+	return brago.Bracket(
+		func() (*sql.Stmt, error) { return db.Prepare(query) },
+		(*sql.Stmt).Close,
+		func(stmt *sql.Stmt) error {
+			res, err := stmt.Exec(args...)
+			if err != nil {
+				return err
+			}
+			return use(res)
+		},
+	)
+}
+
+// PoolConfig configures the connection pool of [WithTunedDB]. Zero fields leave the corresponding
+// settings at their defaults.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// WithTunedDB is a wrapper for [pkg/database/sql.Open] which configures the connection pool of the
+// database with cfg.
+func WithTunedDB(driver, dsn string, cfg PoolConfig, use func(*sql.DB) error) error {
+	return brago.WithResource(
+		func() (*sql.DB, error) {
+			db, err := sql.Open(driver, dsn)
+			if err != nil {
+				return nil, err
+			}
+
+			if cfg.MaxOpenConns != 0 {
+				db.SetMaxOpenConns(cfg.MaxOpenConns)
+			}
+			if cfg.MaxIdleConns != 0 {
+				db.SetMaxIdleConns(cfg.MaxIdleConns)
+			}
+			if cfg.ConnMaxLifetime != 0 {
+				db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+			}
+			if cfg.ConnMaxIdleTime != 0 {
+				db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+			}
+
+			return db, nil
+		},
+		use,
+	)
+}
+
+// WithBatchInsert executes the prepared query once for every row of args in a single transaction
+// and passes the total number of affected rows to use. If any row fails, the transaction is rolled
+// back and use is not run. The statement is closed before the transaction ends.
+func WithBatchInsert(db *sql.DB, query string, rows [][]any, use func(inserted int64) error) error {
+	var inserted int64
+	err := withTx(db, func(tx *sql.Tx) error {
+		return brago.Bracket(
+			func() (*sql.Stmt, error) { return tx.Prepare(query) },
+			(*sql.Stmt).Close,
+			func(stmt *sql.Stmt) error {
+				for _, args := range rows {
+					res, err := stmt.Exec(args...)
+					if err != nil {
+						return err
+					}
+
+					n, err := res.RowsAffected()
+					if err != nil {
+						return err
+					}
+					inserted += n
+				}
+				return nil
+			},
+		)
+	})
+	if err != nil {
+		return err
+	}
+
+	return use(inserted)
+}
+
+// LockDialect is the SQL of a database which takes and releases an advisory lock for
+// [WithMigrationLockDialect]. Both statements get the ID of the lock as their only argument.
+type LockDialect struct {
+	Lock   string
+	Unlock string
+}
+
+// PostgresLock takes a session-level advisory lock of PostgreSQL.
+var PostgresLock = LockDialect{
+	Lock:   "SELECT pg_advisory_lock($1)",
+	Unlock: "SELECT pg_advisory_unlock($1)",
+}
+
+// WithMigrationLock is like [WithMigrationLockDialect] with [PostgresLock].
+func WithMigrationLock(db *sql.DB, lockID int64, use func() error) error {
+	return WithMigrationLockDialect(db, PostgresLock, lockID, use)
+}
+
+// WithMigrationLockDialect holds the advisory lock of lockID while use runs, so concurrent instances
+// do not run their migrations at the same time. The lock is taken and released on a single
+// connection dedicated to it, since advisory locks belong to the session which took them. The lock
+// is released even if use fails.
+func WithMigrationLockDialect(db *sql.DB, dialect LockDialect, lockID int64, use func() error) error {
+	ctx := context.Background()
+
+	return brago.Bracket(
+		func() (*sql.Conn, error) {
+			conn, err := db.Conn(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, err = conn.ExecContext(ctx, dialect.Lock, lockID); err != nil {
+				// MUST NOT leak the connection if the lock cannot be taken!
+				return nil, errors.Join(err, conn.Close())
+			}
+
+			return conn, nil
+		},
+		func(conn *sql.Conn) error {
+			_, err := conn.ExecContext(ctx, dialect.Unlock, lockID)
+			return errors.Join(err, conn.Close())
+		},
+		func(*sql.Conn) error { return use() },
+	)
+}
+
+// withTx runs use in a transaction, which is committed if use succeeds and rolled back otherwise.
+func withTx(db *sql.DB, use func(*sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err = use(tx); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,39 @@
+package sync_test
+
+import (
+	"fmt"
+	"sync"
+
+	bsync "github.com/thelissimus/brago/sync"
+)
+
+func ExampleWithLocks() {
+	var a, b sync.Mutex
+	balance := map[string]int{"alice": 100, "bob": 100}
+
+	transfer := func(from, to *sync.Mutex, fromName, toName string) {
+		bsync.WithLocks([]*sync.Mutex{from, to}, func() error {
+			balance[fromName]--
+			balance[toName]++
+			return nil
+		})
+	}
+
+	// The goroutines lock the same mutexes in opposite orders, which would deadlock with plain
+	// Lock calls.
+	var wg sync.WaitGroup
+	for i := 0; i < 1000; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			transfer(&a, &b, "alice", "bob")
+		}()
+		go func() {
+			defer wg.Done()
+			transfer(&b, &a, "bob", "alice")
+		}()
+	}
+	wg.Wait()
+	fmt.Println(balance["alice"], balance["bob"])
+	// Output: 100 100
+}
@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+/* Wrappers of brago for stdlib sync package. */
+package sync
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"unsafe"
+
+	"github.com/thelissimus/brago"
+)
+
+// WithLocks locks all of mus, runs use and unlocks them in reverse order afterwards. The mutexes
+// are always locked in the order of their addresses, whatever their order in mus, so any number of
+// goroutines locking overlapping sets of mutexes with WithLocks cannot deadlock each other. A mutex
+// listed more than once is locked once.
+func WithLocks(mus []*sync.Mutex, use func() error) error {
+	return brago.Bracket(
+		func() ([]*sync.Mutex, error) {
+			sorted := slices.SortedFunc(slices.Values(mus), func(a, b *sync.Mutex) int {
+				return cmp.Compare(uintptr(unsafe.Pointer(a)), uintptr(unsafe.Pointer(b)))
+			})
+			sorted = slices.Compact(sorted)
+
+			for _, mu := range sorted {
+				mu.Lock()
+			}
+			return sorted, nil
+		},
+		func(sorted []*sync.Mutex) error {
+			for i := len(sorted) - 1; i >= 0; i-- {
+				sorted[i].Unlock()
+			}
+			return nil
+		},
+		func([]*sync.Mutex) error { return use() },
+	)
+}